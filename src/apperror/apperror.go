@@ -0,0 +1,141 @@
+package apperror
+
+import (
+	"errors"
+	"history_anime/src/entity"
+	"net/http"
+)
+
+// Error is an error that can be shown to clients. Code is stable and meant to
+// be branched on, Message is the i18n key of the public message and Cause is
+// the underlying error, which is only logged. Errors and Extensions end up as
+// extra members of the problem document.
+type Error struct {
+	Status     int
+	Code       string
+	Message    string
+	Cause      error
+	Errors     entity.FieldErrors
+	Extensions map[string]interface{}
+}
+
+func New(status int, code string, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+
+	if e.Cause != nil {
+		return e.Code + ": " + e.Cause.Error()
+	}
+
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is matches any *Error with the same code, so errors.Is(err, ErrAnimeNotFound)
+// holds for wrapped copies too.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// As returns err as an *Error. Anything else is reported as ErrInternal so
+// its text never reaches the client.
+func As(err error) *Error {
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return ErrInternal.Wrap(err)
+}
+
+// Wrap returns a copy of e caused by cause.
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.Cause = cause
+	return &copied
+}
+
+// WithErrors returns a copy of e carrying the field errors errs.
+func (e *Error) WithErrors(errs entity.FieldErrors) *Error {
+	copied := *e
+	copied.Errors = errs
+	return &copied
+}
+
+// With returns a copy of e with the extension member key set to value.
+func (e *Error) With(key string, value interface{}) *Error {
+
+	copied := *e
+	copied.Extensions = map[string]interface{}{}
+	for k, v := range e.Extensions {
+		copied.Extensions[k] = v
+	}
+	copied.Extensions[key] = value
+
+	return &copied
+}
+
+var ErrInternal = New(http.StatusInternalServerError, "INTERNAL_ERROR", "internal server error")
+var ErrNotFound = New(http.StatusNotFound, "NOT_FOUND", "not found")
+
+var ErrUnauthorized = New(http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized")
+var ErrTokenExpired = New(http.StatusUnauthorized, "TOKEN_EXPIRED", "token expired")
+var ErrNotLoggedIn = New(http.StatusUnauthorized, "NOT_LOGGED_IN", "user is not logged in")
+var ErrInvalidCredentials = New(http.StatusBadRequest, "INVALID_CREDENTIALS", "check email or password")
+var ErrEmailExists = New(http.StatusConflict, "EMAIL_EXISTS", "email already exists")
+var ErrUsernameExists = New(http.StatusConflict, "USERNAME_EXISTS", "username already exists")
+var ErrResetTokenInvalid = New(http.StatusBadRequest, "RESET_TOKEN_INVALID", "token invalid")
+var ErrResetTokenExpired = New(http.StatusBadRequest, "RESET_TOKEN_EXPIRED", "token expired")
+
+var ErrContentTypeJSON = New(http.StatusUnsupportedMediaType, "CONTENT_TYPE_INVALID", "content-type must be application/json")
+var ErrContentTypeMultipart = New(http.StatusUnsupportedMediaType, "CONTENT_TYPE_INVALID", "content-type must be multipart/form-data")
+var ErrContentTypePatch = New(http.StatusUnsupportedMediaType, "CONTENT_TYPE_INVALID", "content-type must be application/merge-patch+json or application/json-patch+json")
+var ErrBodyUnreadable = New(http.StatusBadRequest, "INVALID_BODY", "request body could not be read")
+var ErrBodyEmpty = New(http.StatusBadRequest, "INVALID_JSON", "request body must not be empty")
+var ErrBodyTooLarge = New(http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE", "request body is too large")
+var ErrInvalidJSON = New(http.StatusBadRequest, "INVALID_JSON", "request body is not valid json")
+var ErrTrailingData = New(http.StatusBadRequest, "INVALID_JSON", "request body must only contain a single json value")
+var ErrUnknownField = New(http.StatusBadRequest, "UNKNOWN_FIELD", "request body contains an unknown field")
+var ErrInvalidPatch = New(http.StatusBadRequest, "INVALID_PATCH", "patch could not be applied")
+var ErrInvalidForm = New(http.StatusBadRequest, "INVALID_FORM", "request form could not be read")
+var ErrFileRequired = New(http.StatusBadRequest, "FILE_REQUIRED", "file is required")
+var ErrImportTooLarge = New(http.StatusRequestEntityTooLarge, "IMPORT_TOO_LARGE", "decompressed file is too large")
+var ErrInvalidFile = New(http.StatusBadRequest, "INVALID_FILE", "file could not be read")
+var ErrValidation = New(http.StatusBadRequest, "VALIDATION_FAILED", "validation failed")
+
+var ErrAtomicInvalid = New(http.StatusBadRequest, "INVALID_QUERY", "atomic must be true or false")
+var ErrLimitInvalid = New(http.StatusBadRequest, "INVALID_QUERY", "limit must be a number")
+var ErrPageInvalid = New(http.StatusBadRequest, "INVALID_QUERY", "page must be a number")
+var ErrCursorInvalid = New(http.StatusBadRequest, "CURSOR_INVALID", "cursor invalid")
+
+var ErrAnimeNotFound = New(http.StatusNotFound, "ANIME_NOT_FOUND", "anime not found")
+var ErrAnimeIdInvalid = New(http.StatusBadRequest, "ANIME_ID_INVALID", "anime id invalid")
+var ErrProgressConflict = New(http.StatusConflict, "PROGRESS_CONFLICT", "progress was changed by another request, try again")
+var ErrBulkValidation = New(http.StatusBadRequest, "BULK_VALIDATION_FAILED", "bulk validation failed")
+var ErrBulkAborted = New(http.StatusConflict, "BULK_ABORTED", "bulk write aborted, no changes were applied")
+
+var ErrEpisodeNotFound = New(http.StatusNotFound, "EPISODE_NOT_FOUND", "episode not found")
+var ErrEpisodeIdInvalid = New(http.StatusBadRequest, "EPISODE_ID_INVALID", "episode id invalid")
+
+var ErrGenreNotFound = New(http.StatusNotFound, "GENRE_NOT_FOUND", "genre not found")
+var ErrGenreIdInvalid = New(http.StatusBadRequest, "GENRE_ID_INVALID", "genre id invalid")
+var ErrGenreNameExists = New(http.StatusConflict, "GENRE_NAME_EXISTS", "genre name already exists")
+var ErrGenreInUse = New(http.StatusConflict, "GENRE_IN_USE", "genre is still used by anime, use strategy=detach or strategy=reassign")
+var ErrGenreTargetNotFound = New(http.StatusBadRequest, "GENRE_TARGET_NOT_FOUND", "target genre not found")
+var ErrGenreParentNotFound = New(http.StatusBadRequest, "GENRE_PARENT_NOT_FOUND", "parent genre not found")
+var ErrGenreCycle = New(http.StatusBadRequest, "GENRE_CYCLE", "genre cannot be moved under itself or one of its descendants")
+
+var ErrTagNotFound = New(http.StatusNotFound, "TAG_NOT_FOUND", "tag not found")
+var ErrTagIdInvalid = New(http.StatusBadRequest, "TAG_ID_INVALID", "tag id invalid")
+var ErrTagNameExists = New(http.StatusConflict, "TAG_NAME_EXISTS", "tag name already exists")
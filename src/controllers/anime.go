@@ -1,127 +1,306 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
+	"history_anime/src/apperror"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"history_anime/src/db"
+	"history_anime/src/entity"
+	"history_anime/src/i18n"
 	"history_anime/src/logger"
 	"history_anime/src/repository"
 	"history_anime/src/requestbody"
 	"history_anime/src/response"
+	"history_anime/src/utility"
 	"history_anime/src/validation"
-	"io"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 var AnimeAdd httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	if r.Header.Get("Content-Type") != "application/json" {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"content-type must be application/json"},
-		})
+	lang := i18n.Language(r)
 
+	body := requestbody.Anime{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Content Type",
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateAnime(&body)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn("Content Type Not Allowed")
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
 		return
+	}
 
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
 	}
 
-	bodyByte, err := io.ReadAll(r.Body)
+	missing, err := repository.GenreRepo(db.DB).Missing(ctx, body.GenreIds)
 	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
 
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
+	errResult = validation.ValidateGenreRefs(missing)
+	if len(errResult) > 0 {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error io.ReadAll",
+			"action": "Validation error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
 		return
 	}
 
-	body := requestbody.Anime{}
-	err = json.Unmarshal(bodyByte, &body)
+	err = repository.TagRepo(db.DB).Ensure(ctx, user.Id, body.Tags)
 	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
 
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+	anime := repository.AnimeRepo(db.DB)
+	insertID, err := anime.Add(ctx, user.Id, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	res, err := json.Marshal(response.AnimeInsert{
+		Message:    i18n.T(lang, "insert anime success"),
+		InsertedID: insertID,
+	})
 
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error json.Unmarshal",
-			"status": http.StatusText(http.StatusBadRequest),
+			"action": "Error json.Marshal",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+var AnimeUpdate httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	body := requestbody.Anime{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, err)
 		return
 	}
 
 	errResult := validation.ValidateAnime(&body)
 	if len(errResult) > 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: errResult,
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Validation error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(strings.Join(errResult, " "))
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
 		return
 	}
 
-	ctx := context.Background()
 	anime := repository.AnimeRepo(db.DB)
-	insertID, err := anime.Add(ctx, &body)
+	current, err := anime.GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err == mongo.ErrNoDocuments {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
 
+	missing, err := repository.GenreRepo(db.DB).Missing(ctx, body.GenreIds)
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
-	res, err := json.Marshal(response.AnimeInsert{
-		Message:    "insert anime success",
-		InsertedID: insertID,
-	})
+	errResult = validation.ValidateGenreRefs(missing)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
 
+	errResult = validation.ValidateStatusTransition(current.Status, entity.AnimeStatus(body.Status))
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	// a status sent by the client wins, otherwise it follows the progress
+	if entity.AnimeStatus(body.Status) == current.Status {
+		body.Status = string(current.Status.ForProgress(body.WatchedEpisodes, body.TotalEpisodes))
+	}
+
+	err = repository.TagRepo(db.DB).Ensure(ctx, user.Id, body.Tags)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
 
+	result, err := anime.Update(ctx, user.Id, &body, current)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Anime Not Found")
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	res, err := json.Marshal(response.Msg{Message: i18n.T(lang, "update anime success")})
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error json.Marshal",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -134,122 +313,390 @@ var AnimeAdd httprouter.Handle = func(w http.ResponseWriter, r *http.Request, pa
 	response.SendJSONResponse(w, http.StatusOK, res)
 }
 
-var AnimeUpdate httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+var AnimePatch httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	if r.Header.Get("Content-Type") != "application/json" {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"content-type must be application/json"},
-		})
+	lang := i18n.Language(r)
 
+	contentType := requestbody.MediaType(r)
+	if contentType != "application/merge-patch+json" && contentType != "application/json-patch+json" {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Content Type",
-			"status": http.StatusText(http.StatusBadRequest),
+			"status": http.StatusText(http.StatusUnsupportedMediaType),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn("Content Type Not Allowed")
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		w.Header().Set("Accept-Patch", "application/merge-patch+json, application/json-patch+json")
+		response.SendError(w, r, apperror.ErrContentTypePatch)
 		return
 	}
 
-	bodyByte, err := io.ReadAll(r.Body)
+	bodyByte, err := requestbody.Read(w, r)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Read Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
 
+	anime := repository.AnimeRepo(db.DB)
+	current, err := anime.GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error io.ReadAll",
+			"action": "ObjectID Mongodb Invalid",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err == mongo.ErrNoDocuments {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
 		return
 	}
 
-	body := requestbody.Anime{}
-	err = json.Unmarshal(bodyByte, &body)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	genreIDs := []string{}
+	for _, genreID := range current.GenreIds {
+		genreIDs = append(genreIDs, genreID.Hex())
+	}
+
+	currentByte, _ := json.Marshal(requestbody.Anime{
+		Name:            current.Name,
+		GenreIds:        genreIDs,
+		Tags:            current.Tags,
+		Description:     current.Description,
+		Image:           current.Image,
+		Status:          string(current.Status),
+		TotalEpisodes:   current.TotalEpisodes,
+		WatchedEpisodes: current.WatchedEpisodes,
+	})
 
+	var patchedByte []byte
+	var fields []string
+	if contentType == "application/merge-patch+json" {
+		patchedByte, fields, err = utility.MergePatch(currentByte, bodyByte)
+	} else {
+		patchedByte, fields, err = utility.JSONPatch(currentByte, bodyByte)
+	}
+
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error json.Unmarshal",
+			"action": "Error Apply Patch",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, apperror.ErrInvalidPatch.Wrap(err))
 		return
 	}
 
-	errResult := validation.ValidateAnime(&body)
+	body := requestbody.Anime{}
+	err = requestbody.Unmarshal(patchedByte, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateAnimePartial(&body, fields)
 	if len(errResult) > 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: errResult,
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	for _, field := range fields {
+		if field != "genre_ids" {
+			continue
+		}
+
+		missing, err := repository.GenreRepo(db.DB).Missing(ctx, body.GenreIds)
+		if err != nil {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Database Error",
+				"status": http.StatusText(http.StatusInternalServerError),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Error(err.Error())
+			response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+			return
+		}
+
+		errResult = validation.ValidateGenreRefs(missing)
+		if len(errResult) > 0 {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Validation error",
+				"status": http.StatusText(http.StatusBadRequest),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Warn(errResult.String())
+			response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+			return
+		}
+	}
 
+	errResult = validation.ValidateStatusTransition(current.Status, entity.AnimeStatus(body.Status))
+	if len(errResult) > 0 {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Validation error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(strings.Join(errResult, " "))
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
 		return
 	}
 
-	ctx := context.Background()
-	anime := repository.AnimeRepo(db.DB)
+	// a status sent by the client wins, otherwise it follows the progress
+	if entity.AnimeStatus(body.Status) == current.Status {
+		status := current.Status.ForProgress(body.WatchedEpisodes, body.TotalEpisodes)
+		if status != current.Status {
+			body.Status = string(status)
 
-	result, err := anime.Update(ctx, &body, params.ByName("id"))
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+			patched := false
+			for _, field := range fields {
+				patched = patched || field == "status"
+			}
+			if !patched {
+				fields = append(fields, "status")
+			}
+		}
+	}
+
+	for _, field := range fields {
+		if field != "tags" {
+			continue
+		}
+
+		err = repository.TagRepo(db.DB).Ensure(ctx, user.Id, body.Tags)
+		if err != nil {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Database Error",
+				"status": http.StatusText(http.StatusInternalServerError),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Error(err.Error())
+			response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+			return
+		}
+	}
 
+	result, err := anime.Patch(ctx, user.Id, &body, fields, current)
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	if result.MatchedCount == 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"anime not found"},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Anime Not Found")
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	res, _ := json.Marshal(response.Msg{Message: i18n.T(lang, "patch anime success")})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+var AnimeProgress httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	body := requestbody.Progress{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateProgress(&body)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	current, err := anime.GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
 
+	if err == mongo.ErrNoDocuments {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Anime Not Found",
 			"status": http.StatusText(http.StatusNotFound),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusNotFound, res)
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
 		return
 	}
 
-	res, err := json.Marshal(response.Msg{Message: "update anime success"})
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	step := 1
+	if body.Value != nil {
+		step = *body.Value
+	}
 
+	watched := current.WatchedEpisodes
+	switch body.Action {
+	case "increment":
+		watched += step
+	case "decrement":
+		watched -= step
+	case "set":
+		watched = step
+	}
+
+	errResult = validation.ValidateProgressRange(watched, current.TotalEpisodes)
+	if len(errResult) > 0 {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error json.Marshal",
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	status := current.Status.ForProgress(watched, current.TotalEpisodes)
+
+	result, err := anime.SetProgress(ctx, user.Id, current, watched, status)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
+	if result.MatchedCount == 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Progress Conflict",
+			"status": http.StatusText(http.StatusConflict),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Progress Conflict")
+		response.SendError(w, r, apperror.ErrProgressConflict)
+		return
+	}
+
+	current.WatchedEpisodes = watched
+	current.Status = status
+
+	res, _ := json.Marshal(response.AnimeOne{
+		Message: i18n.T(lang, "update progress success"),
+		Data:    *current,
+	})
+
 	logger.New().WithFields(logrus.Fields{
 		"action": "Success",
 		"status": http.StatusText(http.StatusOK),
@@ -261,75 +708,362 @@ var AnimeUpdate httprouter.Handle = func(w http.ResponseWriter, r *http.Request,
 
 var AnimeDel httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	ctx := context.Background()
+	lang := i18n.Language(r)
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
 	anime := repository.AnimeRepo(db.DB)
-	result, err := anime.Del(ctx, params.ByName("id"))
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+	result, err := anime.Del(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
 
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	if result.DeletedCount == 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"anime not found"},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Anime Not Found")
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	res, _ := json.Marshal(response.Msg{
+		Message: i18n.T(lang, "delete anime success"),
+	})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+var AnimeGetByID httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
+	lang := i18n.Language(r)
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	result, err := anime.GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err == mongo.ErrNoDocuments {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Anime Not Found",
 			"status": http.StatusText(http.StatusNotFound),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusNotFound, res)
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
 		return
 	}
 
-	res, _ := json.Marshal(response.Msg{
-		Message: "delete anime success",
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	res, err := json.Marshal(response.AnimeOne{
+		Message: i18n.T(lang, "detail anime"),
+		Data:    *result,
 	})
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error json.Marshal",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	etag := response.ETag(res)
+	lastModified := result.Updated_at
+	if lastModified.IsZero() {
+		lastModified = result.Created_at
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	if response.NotModified(r, etag, lastModified) {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Not Modified",
+			"status": http.StatusText(http.StatusNotModified),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Info("Request Success")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 
 	logger.New().WithFields(logrus.Fields{
 		"action": "Success",
 		"status": http.StatusText(http.StatusOK),
 		"path":   r.URL.Path,
 		"method": r.Method,
-	}).Info(err.Error())
+	}).Info("Request Success")
 	response.SendJSONResponse(w, http.StatusOK, res)
 }
 
 var AnimeGetAll httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	ctx := context.Background()
-	anime := repository.AnimeRepo(db.DB)
-	result, err := anime.GetAll(ctx)
+	lang := i18n.Language(r)
 
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	query, err := animeQueryFromURL(r.URL.Query())
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Query Parse Error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateAnimeQuery(&query)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	result, total, nextCursor, err := anime.GetAll(ctx, user.Id, &query)
+	if err == repository.ErrCursorInvalid {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Cursor Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrCursorInvalid.Wrap(err))
+		return
+	}
 
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	res, _ := json.Marshal(response.AnimeAll{
-		Message: "all data anime",
+		Message:    i18n.T(lang, "all data anime"),
+		Data:       result,
+		Total:      total,
+		Page:       query.Page,
+		Limit:      query.Limit,
+		NextCursor: nextCursor,
+	})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+func animeQueryFromURL(values url.Values) (requestbody.AnimeQuery, error) {
+
+	query := requestbody.AnimeQuery{
+		Page:   1,
+		Limit:  20,
+		Cursor: values.Get("cursor"),
+		Status: values.Get("status"),
+		Genre:  values.Get("genre"),
+		Tag:    values.Get("tag"),
+		Sort:   "created_at",
+		Order:  "desc",
+	}
+
+	if page := values.Get("page"); page != "" {
+		pageInt, err := strconv.Atoi(page)
+		if err != nil {
+			return query, apperror.ErrPageInvalid.Wrap(err)
+		}
+		query.Page = pageInt
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil {
+			return query, apperror.ErrLimitInvalid.Wrap(err)
+		}
+		query.Limit = limitInt
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		query.Sort = sort
+	}
+
+	if order := values.Get("order"); order != "" {
+		query.Order = order
+	}
+
+	return query, nil
+}
+
+var AnimeSearch httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	values := r.URL.Query()
+	query := requestbody.AnimeSearch{
+		Q:      strings.TrimSpace(values.Get("q")),
+		Status: values.Get("status"),
+		Genre:  values.Get("genre"),
+		Limit:  20,
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		limitInt, err := strconv.Atoi(limit)
+		if err != nil {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Query Parse Error",
+				"status": http.StatusText(http.StatusBadRequest),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Warn(err.Error())
+			response.SendError(w, r, apperror.ErrLimitInvalid)
+			return
+		}
+		query.Limit = limitInt
+	}
+
+	errResult := validation.ValidateAnimeSearch(&query)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	result, err := anime.Search(ctx, user.Id, &query)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	terms := utility.SearchTerms(query.Q)
+	for i := range result {
+		result[i].Highlight = entity.AnimeHighlight{
+			Name:        utility.Highlight(result[i].Name, terms),
+			Description: utility.Highlight(result[i].Description, terms),
+		}
+	}
+
+	res, _ := json.Marshal(response.AnimeSearch{
+		Message: i18n.T(lang, "search anime result"),
 		Data:    result,
 	})
 
@@ -338,6 +1072,6 @@ var AnimeGetAll httprouter.Handle = func(w http.ResponseWriter, r *http.Request,
 		"status": http.StatusText(http.StatusOK),
 		"path":   r.URL.Path,
 		"method": r.Method,
-	}).Info(err.Error())
+	}).Info("Request Success")
 	response.SendJSONResponse(w, http.StatusOK, res)
 }
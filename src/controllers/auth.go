@@ -3,22 +3,24 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 
+	"history_anime/src/apperror"
 	"history_anime/src/db"
 	"history_anime/src/entity"
+	"history_anime/src/i18n"
 	"history_anime/src/logger"
 	"history_anime/src/repository"
 	"history_anime/src/requestbody"
 	"history_anime/src/response"
 	"history_anime/src/utility"
 	"history_anime/src/validation"
-	"io"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
@@ -29,46 +31,45 @@ import (
 
 var Register httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	result, err := io.ReadAll(r.Body)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+	lang := i18n.Language(r)
 
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+	body := requestbody.Register{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
 		return
 	}
 
-	body := requestbody.Register{}
-	err = json.Unmarshal(result, &body)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+	errResult := validation.ValidateRegister(&body)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation Error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
 		return
 	}
 
 	res, err := json.Marshal(response.Msg{
-		Message: "Registrasi berhasil",
+		Message: i18n.T(lang, "register success"),
 	})
 
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(body.Password), 10)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -77,12 +78,19 @@ var Register httprouter.Handle = func(w http.ResponseWriter, r *http.Request, pa
 	ctx := context.Background()
 	con := repository.AuthRepo(db.DB)
 	err = con.Register(ctx, &body)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
 
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+	var duplicate *repository.DuplicateKeyError
+	if errors.As(err, &duplicate) {
+		appErr := apperror.ErrEmailExists
+		if duplicate.Field == "username" {
+			appErr = apperror.ErrUsernameExists
+		}
+		response.SendError(w, r, appErr.Wrap(err))
+		return
+	}
+
+	if err != nil {
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -91,52 +99,30 @@ var Register httprouter.Handle = func(w http.ResponseWriter, r *http.Request, pa
 
 var Login httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	result, err := io.ReadAll(r.Body)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
-		logger.New().WithFields(logrus.Fields{
-			"action": "Error io.ReadAll",
-			"status": http.StatusText(http.StatusBadRequest),
-			"path":   r.URL.Path,
-			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
-		return
-	}
+	lang := i18n.Language(r)
 
 	body := requestbody.Login{}
-	err = json.Unmarshal(result, &body)
+	err := requestbody.Decode(w, r, &body)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error json.Unmarshal",
-			"status": http.StatusText(http.StatusBadRequest),
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, err)
 		return
 	}
 
 	errResult := validation.ValidateLogin(&body)
 	if len(errResult) > 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: errResult,
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Validation Error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
 		return
 	}
 
@@ -146,62 +132,52 @@ var Login httprouter.Handle = func(w http.ResponseWriter, r *http.Request, param
 
 	if err != nil {
 		if err.Error() == mongo.ErrNoDocuments.Error() {
-			res, _ := json.Marshal(response.Errors{
-				Errors: []string{"check email or password"},
-			})
-
 			logger.New().WithFields(logrus.Fields{
 				"action": "Error No Document",
 				"status": http.StatusText(http.StatusBadRequest),
 				"path":   r.URL.Path,
 				"method": r.Method,
 			}).Warn(err.Error())
-			response.SendJSONResponse(w, http.StatusBadRequest, res)
+			response.SendError(w, r, apperror.ErrInvalidCredentials)
 			return
 		}
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password))
 	if err == bcrypt.ErrMismatchedHashAndPassword {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"check email or password"},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error Bcrypt Mismatch",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, apperror.ErrInvalidCredentials)
 		return
 	}
 
+	// the session starts in the language the user picked, if any, only once the
+	// password matched so the language doesn't reveal which emails exist
+	if i18n.Supported(user.Language) {
+		lang = user.Language
+	}
+
 	token, err := utility.CreateToken(os.Getenv("SECRET_KEY"), user.Id.Hex())
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error Create Token",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -216,7 +192,7 @@ var Login httprouter.Handle = func(w http.ResponseWriter, r *http.Request, param
 	}
 
 	res, _ := json.Marshal(response.Login{
-		Message: "login success",
+		Message: i18n.T(lang, "login success"),
 		Token:   token,
 	})
 
@@ -232,6 +208,8 @@ var Login httprouter.Handle = func(w http.ResponseWriter, r *http.Request, param
 
 var Logout httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
+	lang := i18n.Language(r)
+
 	cookie := http.Cookie{
 		Name:     "token",
 		Value:    "",
@@ -242,7 +220,7 @@ var Logout httprouter.Handle = func(w http.ResponseWriter, r *http.Request, para
 		SameSite: http.SameSiteNoneMode,
 	}
 
-	res, _ := json.Marshal(response.Msg{Message: "logout success"})
+	res, _ := json.Marshal(response.Msg{Message: i18n.T(lang, "logout success")})
 
 	logger.New().WithFields(logrus.Fields{
 		"action": "Success",
@@ -256,128 +234,91 @@ var Logout httprouter.Handle = func(w http.ResponseWriter, r *http.Request, para
 
 var ForgotPassword httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	if r.Header.Get("Content-Type") != "application/json" {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"content-type must be application/json"},
-		})
-
-		logger.New().WithFields(logrus.Fields{
-			"action": "Content Type",
-			"status": http.StatusText(http.StatusBadRequest),
-			"path":   r.URL.Path,
-			"method": r.Method,
-		}).Warn("Content Type Not Allowed")
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
-		return
-	}
-
-	bodyByte, err := io.ReadAll(r.Body)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
-		logger.New().WithFields(logrus.Fields{
-			"action": "Error io.ReadAll",
-			"status": http.StatusText(http.StatusBadRequest),
-			"path":   r.URL.Path,
-			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
-		return
-	}
+	lang := i18n.Language(r)
 
 	body := requestbody.ForgotPassword{}
-	err = json.Unmarshal(bodyByte, &body)
+	err := requestbody.Decode(w, r, &body)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error json.Unmarshal",
-			"status": http.StatusText(http.StatusBadRequest),
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, err)
 		return
 	}
 
 	errResult := validation.ValidateForgotPassword(&body)
 	if len(errResult) > 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: errResult,
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Validation error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(strings.Join(errResult, " "))
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
 		return
 	}
 
 	token, err := utility.CreateTokenForgotPassword(os.Getenv("SECRET_KEY"), body.Email)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: errResult,
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error Create Token",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	url := fmt.Sprintf("%s/reset-password/%s", os.Getenv("CLIENT_URL_HOST"), token)
+	html, err := i18n.Email(lang, "reset_password", map[string]interface{}{
+		"URL":     url,
+		"Minutes": int(utility.ForgotPasswordTTL.Minutes()),
+	})
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Render Email",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
 	err = utility.SendEmail(utility.Email{
 		From:    "History Anime",
 		To:      body.Email,
-		Subject: "reset password",
-		Html: fmt.Sprintf(`
-			<p style="font-weight: bold;">silahkan reset password anda <a href="%s" target="_blank"> reset password </a> </p>
-		  <p style="font-weight: bold;"> link berlaku 10 menit </p>
-		`, url),
+		Subject: i18n.T(lang, "reset password"),
+		Html:    html,
 	})
 
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Erorr Send Email",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	res, err := json.Marshal(response.Msg{
-		Message: "email has been sent and check your email",
+		Message: i18n.T(lang, "email has been sent and check your email"),
 	})
 
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error json.Marshal",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -392,100 +333,59 @@ var ForgotPassword httprouter.Handle = func(w http.ResponseWriter, r *http.Reque
 
 var ResetPassword httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	if r.Header.Get("Content-Type") != "application/json" {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"content-type must be application/json"},
-		})
-
-		logger.New().WithFields(logrus.Fields{
-			"action": "Content Type",
-			"status": http.StatusText(http.StatusBadRequest),
-			"path":   r.URL.Path,
-			"method": r.Method,
-		}).Warn("Content Type Not Allowed")
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
-		return
-	}
-
-	bodyByte, err := io.ReadAll(r.Body)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
-		logger.New().WithFields(logrus.Fields{
-			"action": "Error io.ReadAll",
-			"status": http.StatusText(http.StatusBadRequest),
-			"path":   r.URL.Path,
-			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
-		return
-	}
+	lang := i18n.Language(r)
 
 	body := requestbody.ResetPassword{}
-	err = json.Unmarshal(bodyByte, &body)
+	err := requestbody.Decode(w, r, &body)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error json.Unmarshal",
-			"status": http.StatusText(http.StatusBadRequest),
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, err)
 		return
 	}
 
 	errResult := validation.ValidateResetPassword(&body)
 	if len(errResult) > 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: errResult,
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Validation error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(strings.Join(errResult, " "))
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
 		return
 	}
 
 	email, err := utility.VerifyTokenForgotPassword(os.Getenv("SECRET_KEY"), body.Token)
 
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error Token Invalid",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		appErr := apperror.ErrResetTokenInvalid
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			appErr = apperror.ErrResetTokenExpired
+		}
+		response.SendError(w, r, appErr.Wrap(err))
 		return
 	}
 
 	newHashPassword, err := bcrypt.GenerateFromPassword([]byte(body.NewPassword), 10)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error Generate Password",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -493,50 +393,38 @@ var ResetPassword httprouter.Handle = func(w http.ResponseWriter, r *http.Reques
 	auth := repository.AuthRepo(db.DB)
 	result, err := auth.ResetPassword(ctx, email, string(newHashPassword))
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	if result.MatchedCount == 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"token invalid"},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "User Not Found",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn("User Not Found")
+		response.SendError(w, r, apperror.ErrResetTokenInvalid)
 		return
 	}
 
 	res, err := json.Marshal(response.Msg{
-		Message: "reset password success",
+		Message: i18n.T(lang, "reset password success"),
 	})
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error json.Marshal",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -551,52 +439,42 @@ var ResetPassword httprouter.Handle = func(w http.ResponseWriter, r *http.Reques
 
 var IsLogin httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
+	lang := i18n.Language(r)
+
 	token, err := r.Cookie("token")
 	if err == http.ErrNoCookie {
 
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"user is not logged in"},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "No Token",
 			"status": http.StatusText(http.StatusUnauthorized),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusUnauthorized, res)
+		response.SendError(w, r, apperror.ErrNotLoggedIn)
 		return
 	}
 
 	id, err := utility.VerifyToken(os.Getenv("SECRET_KEY"), token.Value)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"user is not logged in"},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Token Invalid",
 			"status": http.StatusText(http.StatusUnauthorized),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusUnauthorized, res)
+		response.SendError(w, r, apperror.ErrNotLoggedIn)
 		return
 	}
 
 	objId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"user is not logged in"},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "ObjectID Mongodb Invalid",
 			"status": http.StatusText(http.StatusUnauthorized),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusUnauthorized, res)
+		response.SendError(w, r, apperror.ErrNotLoggedIn)
 		return
 	}
 
@@ -605,49 +483,37 @@ var IsLogin httprouter.Handle = func(w http.ResponseWriter, r *http.Request, par
 	result := entity.Users{}
 	err = db.DB.Collection("users").FindOne(ctx, filter).Decode(&result)
 	if err == mongo.ErrNoDocuments {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"user is not logged in"},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "User Not Found",
 			"status": http.StatusText(http.StatusUnauthorized),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusUnauthorized, res)
+		response.SendError(w, r, apperror.ErrNotLoggedIn)
 		return
 	} else if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	res, err := json.Marshal(response.Msg{
-		Message: "user has been login",
+		Message: i18n.T(lang, "user has been login"),
 	})
 
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error json.Marshal",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -659,3 +525,67 @@ var IsLogin httprouter.Handle = func(w http.ResponseWriter, r *http.Request, par
 	}).Info("Request Success")
 	response.SendJSONResponse(w, http.StatusOK, res)
 }
+
+var LanguageUpdate httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	body := requestbody.Language{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateLanguage(&body)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	err = repository.AuthRepo(db.DB).SetLanguage(ctx, user.Id, body.Language)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	// confirm in the language that was just picked
+	res, _ := json.Marshal(response.Msg{Message: i18n.T(body.Language, "language updated")})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"encoding/json"
+	"history_anime/src/apperror"
+	"history_anime/src/db"
+	"history_anime/src/entity"
+	"history_anime/src/i18n"
+	"history_anime/src/logger"
+	"history_anime/src/repository"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/src/utility"
+	"history_anime/src/validation"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var AnimeBulk httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	body := requestbody.AnimeBulk{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateAnimeBulk(&body)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	atomic := false
+	if value := r.URL.Query().Get("atomic"); value != "" {
+		atomic, err = strconv.ParseBool(value)
+		if err != nil {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Query Parse Error",
+				"status": http.StatusText(http.StatusBadRequest),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Warn(err.Error())
+			response.SendError(w, r, apperror.ErrAtomicInvalid)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	// validate every operation on its own first, then look up what they reference in one go
+	results := make([]entity.AnimeBulkResult, len(body.Operations))
+	ids := []primitive.ObjectID{}
+	genreIDs := []string{}
+	usedBy := map[primitive.ObjectID]int{}
+	for i := range body.Operations {
+		op := &body.Operations[i]
+		results[i] = entity.AnimeBulkResult{Index: i, Op: op.Op}
+
+		errResult := validation.ValidateAnimeBulkOperation(op)
+		if op.Op != "create" && len(errResult) == 0 {
+			id, _ := primitive.ObjectIDFromHex(op.Id)
+			if first, ok := usedBy[id]; ok {
+				errResult = append(errResult, validation.NewFieldError("id", "unique", strconv.Itoa(first), "unique_operation", strconv.Itoa(first)))
+			} else {
+				usedBy[id] = i
+				ids = append(ids, id)
+			}
+		}
+		if op.Op != "delete" && len(errResult) == 0 {
+			genreIDs = append(genreIDs, op.Data.GenreIds...)
+		}
+
+		if len(errResult) > 0 {
+			results[i].Status = entity.BulkError
+			results[i].Errors = errResult.Localize(lang)
+		}
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	current, err := anime.GetMany(ctx, user.Id, ids)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	missing, err := repository.GenreRepo(db.DB).Missing(ctx, genreIDs)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	missingGenres := map[string]bool{}
+	for _, id := range missing {
+		missingGenres[id] = true
+	}
+
+	writes := []repository.AnimeBulkWrite{}
+	invalid := false
+	for i := range body.Operations {
+		if results[i].Status == entity.BulkError {
+			invalid = true
+			continue
+		}
+
+		op := &body.Operations[i]
+		write := repository.AnimeBulkWrite{
+			Index: i,
+			Op:    op.Op,
+			Body:  op.Data,
+		}
+
+		errResult := entity.FieldErrors{}
+		if op.Op != "create" {
+			id, _ := primitive.ObjectIDFromHex(op.Id)
+			data, ok := current[id]
+			if !ok {
+				errResult = append(errResult, validation.NewFieldError("id", "exists", op.Id, "anime_exists"))
+			} else {
+				write.Current = &data
+			}
+		}
+
+		if op.Op != "delete" {
+			unknown := []string{}
+			for _, id := range op.Data.GenreIds {
+				objID, _ := primitive.ObjectIDFromHex(id)
+				if missingGenres[objID.Hex()] {
+					unknown = append(unknown, id)
+				}
+			}
+			errResult = append(errResult, validation.ValidateGenreRefs(unknown)...)
+		}
+
+		if op.Op == "update" && write.Current != nil {
+			errResult = append(errResult, validation.ValidateStatusTransition(write.Current.Status, entity.AnimeStatus(op.Data.Status))...)
+
+			// same rule as a single update, an unchanged status follows the progress
+			if entity.AnimeStatus(op.Data.Status) == write.Current.Status {
+				op.Data.Status = string(write.Current.Status.ForProgress(op.Data.WatchedEpisodes, op.Data.TotalEpisodes))
+			}
+		}
+
+		if len(errResult) > 0 {
+			invalid = true
+			results[i].Status = entity.BulkError
+			results[i].Errors = errResult.Localize(lang)
+			continue
+		}
+
+		writes = append(writes, write)
+	}
+
+	if invalid && atomic {
+		for i := range results {
+			if results[i].Status != entity.BulkError {
+				results[i].Status = entity.BulkSkipped
+			}
+		}
+
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("bulk validation failed")
+		response.SendError(w, r, apperror.ErrBulkValidation.With("results", results))
+		return
+	}
+
+	if len(writes) > 0 {
+		written, err := anime.Bulk(ctx, user.Id, writes, atomic)
+		if err != nil && err != repository.ErrBulkAborted {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Database Error",
+				"status": http.StatusText(http.StatusInternalServerError),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Error(err.Error())
+			response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+			return
+		}
+
+		for _, result := range written {
+			results[result.Index] = result
+		}
+
+		if err == repository.ErrBulkAborted {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Bulk Aborted",
+				"status": http.StatusText(http.StatusConflict),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Warn(err.Error())
+			response.SendError(w, r, apperror.ErrBulkAborted.With("results", results))
+			return
+		}
+	}
+
+	res, err := json.Marshal(response.AnimeBulk{
+		Message: i18n.T(lang, "bulk write success"),
+		Results: results,
+	})
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error json.Marshal",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
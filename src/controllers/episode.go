@@ -0,0 +1,420 @@
+package controllers
+
+import (
+	"encoding/json"
+	"history_anime/src/apperror"
+
+	"history_anime/src/db"
+	"history_anime/src/i18n"
+	"history_anime/src/logger"
+	"history_anime/src/repository"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/src/utility"
+	"history_anime/src/validation"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var EpisodeGetAll httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime, err := repository.AnimeRepo(db.DB).GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err == mongo.ErrNoDocuments {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	episode := repository.EpisodeRepo(db.DB)
+	result, err := episode.GetAll(ctx, user.Id, anime.Id)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	res, _ := json.Marshal(response.EpisodeAll{
+		Message: i18n.T(lang, "all data episode"),
+		Data:    result,
+	})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+var EpisodeAdd httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	body := requestbody.Episode{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime, err := repository.AnimeRepo(db.DB).GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err == mongo.ErrNoDocuments {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	errResult := validation.ValidateEpisode(&body, anime.TotalEpisodes)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	episode := repository.EpisodeRepo(db.DB)
+	insertID, err := episode.Add(ctx, user.Id, anime.Id, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	res, _ := json.Marshal(response.EpisodeInsert{
+		Message:    i18n.T(lang, "insert episode success"),
+		InsertedID: insertID,
+	})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusCreated),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusCreated, res)
+}
+
+var EpisodeUpdate httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	body := requestbody.Episode{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime, err := repository.AnimeRepo(db.DB).GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err == mongo.ErrNoDocuments {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	errResult := validation.ValidateEpisode(&body, anime.TotalEpisodes)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	episode := repository.EpisodeRepo(db.DB)
+	result, err := episode.Update(ctx, user.Id, anime.Id, &body, params.ByName("episodeId"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrEpisodeIdInvalid)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Episode Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Episode Not Found")
+		response.SendError(w, r, apperror.ErrEpisodeNotFound)
+		return
+	}
+
+	res, _ := json.Marshal(response.Msg{Message: i18n.T(lang, "update episode success")})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+var EpisodeDel httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime, err := repository.AnimeRepo(db.DB).GetByID(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err == mongo.ErrNoDocuments {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	episode := repository.EpisodeRepo(db.DB)
+	result, err := episode.Del(ctx, user.Id, anime.Id, params.ByName("episodeId"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrEpisodeIdInvalid)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Episode Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Episode Not Found")
+		response.SendError(w, r, apperror.ErrEpisodeNotFound)
+		return
+	}
+
+	res, _ := json.Marshal(response.Msg{Message: i18n.T(lang, "delete episode success")})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
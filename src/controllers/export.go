@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"fmt"
+	"history_anime/src/apperror"
+	"history_anime/src/db"
+	"history_anime/src/entity"
+	"history_anime/src/logger"
+	"history_anime/src/repository"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/src/utility"
+	"history_anime/src/validation"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+)
+
+var Export httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	query := requestbody.ExportQuery{
+		Format: r.URL.Query().Get("format"),
+	}
+	if query.Format == "" {
+		query.Format = "json"
+	}
+
+	errResult := validation.ValidateExportQuery(&query)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	cur, err := anime.Export(ctx, user.Id)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+	defer cur.Close(ctx)
+
+	format := response.ExportFormats[query.Format]
+	filename := fmt.Sprintf("history-anime-%s.%s", time.Now().Format("20060102"), format.Extension)
+	w.Header().Set("Content-Type", format.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.WriteHeader(http.StatusOK)
+
+	// the status is already sent, failures from here on can only be logged
+	writer := response.NewAnimeWriter(query.Format, w)
+	for cur.Next(ctx) {
+		data := entity.Anime{}
+
+		err := cur.Decode(&data)
+		if err == nil {
+			err = writer.Write(&data)
+		}
+		if err != nil {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Export Aborted",
+				"status": http.StatusText(http.StatusOK),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Error(err.Error())
+			return
+		}
+	}
+
+	if cur.Err() != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Export Aborted",
+			"status": http.StatusText(http.StatusOK),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(cur.Err().Error())
+		return
+	}
+
+	err = writer.Close()
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Export Aborted",
+			"status": http.StatusText(http.StatusOK),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		return
+	}
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+}
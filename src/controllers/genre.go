@@ -1,17 +1,18 @@
 package controllers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
+	"history_anime/src/apperror"
 	"history_anime/src/db"
+	"history_anime/src/i18n"
 	"history_anime/src/logger"
 	"history_anime/src/repository"
 	"history_anime/src/requestbody"
 	"history_anime/src/response"
+	"history_anime/src/utility"
 	"history_anime/src/validation"
-	"io"
 	"net/http"
-	"strings"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
@@ -19,40 +20,34 @@ import (
 
 var GenreGetAll httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	ctx := context.Background()
+	lang := i18n.Language(r)
+
+	ctx := r.Context()
 	genreCol := repository.GenreRepo(db.DB)
 	result, err := genreCol.GetAll(ctx)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	res, err := json.Marshal(response.GenreAll{
-		Message: "all data genre",
+		Message: i18n.T(lang, "all data genre"),
 		Data:    result,
 	})
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error json.Marshal",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -65,108 +60,152 @@ var GenreGetAll httprouter.Handle = func(w http.ResponseWriter, r *http.Request,
 	response.SendJSONResponse(w, http.StatusOK, res)
 }
 
-var GenreAdd httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+var GenreStats httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
-	if r.Header.Get("Content-Type") != "application/json" {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"content-type must be application/json"},
-		})
+	lang := i18n.Language(r)
 
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Content Type",
-			"status": http.StatusText(http.StatusBadRequest),
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn("Content Type Not Allowed")
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
 		return
 	}
 
-	bodyByte, err := io.ReadAll(r.Body)
+	genreCol := repository.GenreRepo(db.DB)
+	result, err := genreCol.Stats(ctx, user.Id)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
 
+	res, err := json.Marshal(response.GenreStats{
+		Message: i18n.T(lang, "genre stats"),
+		Data:    result,
+	})
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error io.ReadAll",
-			"status": http.StatusText(http.StatusBadRequest),
+			"action": "Error json.Marshal",
+			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+var GenreAdd httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
 	body := requestbody.Genre{}
-	err = json.Unmarshal(bodyByte, &body)
+	err := requestbody.Decode(w, r, &body)
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
-			"action": "Error json.Unmarshal",
-			"status": http.StatusText(http.StatusBadRequest),
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		response.SendError(w, r, err)
 		return
 	}
 
 	errResult := validation.ValidateGenre(&body)
 	if len(errResult) > 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: errResult,
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Validation error",
 			"status": http.StatusText(http.StatusBadRequest),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(strings.Join(errResult, " "))
-		response.SendJSONResponse(w, http.StatusBadRequest, res)
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
 		return
 	}
 
-	ctx := context.Background()
 	genreCol := repository.GenreRepo(db.DB)
 
-	insertedID, err := genreCol.Add(ctx, &body)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+	insertedID, err := genreCol.Add(ctx, user.Id, &body)
+	if err == repository.ErrGenreParentNotFound || err == repository.ErrGenreCycle {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Genre Parent Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, genreParentError(err))
+		return
+	}
 
+	var duplicate *repository.DuplicateKeyError
+	if errors.As(err, &duplicate) {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Duplicate Key",
+			"status": http.StatusText(http.StatusConflict),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrGenreNameExists)
+		return
+	}
+
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	res, err := json.Marshal(response.GenreInsert{
-		Message:    "insert genre success",
+		Message:    i18n.T(lang, "insert genre success"),
 		InsertedID: insertedID,
 	})
 
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error json.Marshal",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -175,63 +214,230 @@ var GenreAdd httprouter.Handle = func(w http.ResponseWriter, r *http.Request, pa
 		"status": http.StatusText(http.StatusCreated),
 		"path":   r.URL.Path,
 		"method": r.Method,
-	}).Info(err.Error())
+	}).Info("Request Success")
 	response.SendJSONResponse(w, http.StatusCreated, res)
 }
 
+var GenreUpdate httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	body := requestbody.Genre{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateGenre(&body)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	genreCol := repository.GenreRepo(db.DB)
+	result, err := genreCol.Update(ctx, user.Id, params.ByName("id"), &body)
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrGenreIdInvalid)
+		return
+	}
+
+	if err == repository.ErrGenreParentNotFound || err == repository.ErrGenreCycle {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Genre Parent Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, genreParentError(err))
+		return
+	}
+
+	var duplicate *repository.DuplicateKeyError
+	if errors.As(err, &duplicate) {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Duplicate Key",
+			"status": http.StatusText(http.StatusConflict),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrGenreNameExists)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Genre Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Genre Not Found")
+		response.SendError(w, r, apperror.ErrGenreNotFound)
+		return
+	}
+
+	res, err := json.Marshal(response.Msg{
+		Message: i18n.T(lang, "update genre success"),
+	})
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error json.Marshal",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
 var GenreDelete httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 
+	lang := i18n.Language(r)
+
 	id := params.ByName("id")
 
-	ctx := context.Background()
+	query := requestbody.GenreDelete{
+		Id:       id,
+		Strategy: r.URL.Query().Get("strategy"),
+		To:       r.URL.Query().Get("to"),
+	}
+
+	errResult := validation.ValidateGenreDelete(&query)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
 	genreCol := repository.GenreRepo(db.DB)
-	result, err := genreCol.Del(ctx, id)
-	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
+	result, modified, err := genreCol.Del(ctx, id, &query)
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrGenreIdInvalid)
+		return
+	}
 
+	if err == repository.ErrGenreTargetNotFound {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Genre Not Found",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrGenreTargetNotFound)
+		return
+	}
+
+	var inUse *repository.GenreInUseError
+	if errors.As(err, &inUse) {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Genre In Use",
+			"status": http.StatusText(http.StatusConflict),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrGenreInUse.With("anime_count", inUse.Count).Wrap(err))
+		return
+	}
+
+	if err != nil {
 		logger.New().WithFields(logrus.Fields{
 			"action": "Database Error",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
 	if result.DeletedCount == 0 {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{"genre not found"},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Genre Not Found",
 			"status": http.StatusText(http.StatusNotFound),
 			"path":   r.URL.Path,
 			"method": r.Method,
-		}).Warn(err.Error())
-		response.SendJSONResponse(w, http.StatusNotFound, res)
+		}).Warn("Genre Not Found")
+		response.SendError(w, r, apperror.ErrGenreNotFound)
 		return
 	}
 
-	res, err := json.Marshal(response.Msg{
-		Message: "delete genre success",
+	res, err := json.Marshal(response.GenreDelete{
+		Message:      i18n.T(lang, "delete genre success"),
+		AnimeUpdated: modified,
 	})
 
 	if err != nil {
-		res, _ := json.Marshal(response.Errors{
-			Errors: []string{err.Error()},
-		})
-
 		logger.New().WithFields(logrus.Fields{
 			"action": "Error json.Marshal",
 			"status": http.StatusText(http.StatusInternalServerError),
 			"path":   r.URL.Path,
 			"method": r.Method,
 		}).Error(err.Error())
-		response.SendJSONResponse(w, http.StatusInternalServerError, res)
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 		return
 	}
 
@@ -240,6 +446,16 @@ var GenreDelete httprouter.Handle = func(w http.ResponseWriter, r *http.Request,
 		"status": http.StatusText(http.StatusOK),
 		"path":   r.URL.Path,
 		"method": r.Method,
-	}).Info(err.Error())
+	}).Info("Request Success")
 	response.SendJSONResponse(w, http.StatusOK, res)
 }
+
+// genreParentError picks the problem for the parent errors of Add and Update.
+func genreParentError(err error) *apperror.Error {
+
+	if err == repository.ErrGenreCycle {
+		return apperror.ErrGenreCycle.Wrap(err)
+	}
+
+	return apperror.ErrGenreParentNotFound.Wrap(err)
+}
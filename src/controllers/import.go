@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"history_anime/src/apperror"
+	"history_anime/src/db"
+	"history_anime/src/entity"
+	"history_anime/src/i18n"
+	"history_anime/src/logger"
+	"history_anime/src/repository"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/src/utility"
+	"history_anime/src/validation"
+	"io"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+)
+
+const maxImportSize = 10 << 20
+
+// maxDecompressedImportSize caps what a gzipped upload may expand to, a few
+// kilobytes of gzip can otherwise decompress to gigabytes.
+const maxDecompressedImportSize = 50 << 20
+
+// malImportGenre is used when the upload doesn't name any genre.
+const malImportGenre = "Imported"
+
+var ImportMal httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	if requestbody.MediaType(r) != "multipart/form-data" {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Content Type",
+			"status": http.StatusText(http.StatusUnsupportedMediaType),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Content Type Not Allowed")
+		response.SendError(w, r, apperror.ErrContentTypeMultipart)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	err := r.ParseMultipartForm(maxImportSize)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error ParseMultipartForm",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrInvalidForm.Wrap(err))
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error FormFile",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrFileRequired)
+		return
+	}
+	defer file.Close()
+
+	form := requestbody.Import{
+		Genres: r.MultipartForm.Value["genre"],
+	}
+	if len(form.Genres) == 0 {
+		form.Genres = []string{malImportGenre}
+	}
+
+	errResult := validation.ValidateImport(&form)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	// MyAnimeList hands the export out gzipped, accept it either way
+	buffered := bufio.NewReader(file)
+	var reader io.Reader = buffered
+	magic, _ := buffered.Peek(2)
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		reader, err = gzip.NewReader(reader)
+		if err != nil {
+			logger.New().WithFields(logrus.Fields{
+				"action": "Error gzip.NewReader",
+				"status": http.StatusText(http.StatusBadRequest),
+				"path":   r.URL.Path,
+				"method": r.Method,
+			}).Warn(err.Error())
+			response.SendError(w, r, apperror.ErrInvalidFile.Wrap(err))
+			return
+		}
+	}
+
+	// one byte over the cap tells a file of exactly the cap from a larger one
+	limited := &io.LimitedReader{R: reader, N: maxDecompressedImportSize + 1}
+
+	body := requestbody.MalExport{}
+	err = xml.NewDecoder(limited).Decode(&body)
+	if limited.N <= 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Import Too Large",
+			"status": http.StatusText(http.StatusRequestEntityTooLarge),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Import Too Large")
+		response.SendError(w, r, apperror.ErrImportTooLarge.With("limit", maxDecompressedImportSize))
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error xml.Decode",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrInvalidFile.Wrap(err))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	genreIDs, err := repository.GenreRepo(db.DB).Resolve(ctx, user.Id, form.Genres)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	rows, err := repository.ImportRepo(db.DB).Mal(ctx, user.Id, &body, genreIDs)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	report := response.Import{
+		Message: i18n.T(lang, "import success"),
+		Rows:    rows,
+	}
+	for i, row := range rows {
+		if row.Reason != "" {
+			rows[i].Reason = i18n.T(lang, row.Reason, row.ReasonArgs...)
+		}
+		rows[i].Errors = row.Errors.Localize(lang)
+
+		switch row.Result {
+		case entity.ImportCreated:
+			report.Created++
+		case entity.ImportUpdated:
+			report.Updated++
+		default:
+			report.Skipped++
+		}
+	}
+
+	res, err := json.Marshal(report)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error json.Marshal",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"history_anime/src/apperror"
+	"history_anime/src/logger"
+	"history_anime/src/response"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+)
+
+var NotFound httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Not Found",
+		"status": http.StatusText(http.StatusNotFound),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Warn("Not Found")
+	response.SendError(w, r, apperror.ErrNotFound)
+}
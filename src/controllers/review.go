@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"encoding/json"
+	"history_anime/src/apperror"
+
+	"history_anime/src/db"
+	"history_anime/src/i18n"
+	"history_anime/src/logger"
+	"history_anime/src/repository"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/src/utility"
+	"history_anime/src/validation"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+)
+
+var AnimeReviewSet httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	body := requestbody.Review{}
+	err := requestbody.Decode(w, r, &body)
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Error Decode Body",
+			"status": http.StatusText(apperror.As(err).Status),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, err)
+		return
+	}
+
+	errResult := validation.ValidateReview(&body)
+	if len(errResult) > 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Validation error",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(errResult.String())
+		response.SendError(w, r, apperror.ErrValidation.WithErrors(errResult))
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	result, err := anime.SetReview(ctx, user.Id, &body, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Anime Not Found")
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	res, _ := json.Marshal(response.Msg{Message: i18n.T(lang, "set review success")})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
+
+var AnimeReviewClear httprouter.Handle = func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+
+	lang := i18n.Language(r)
+
+	ctx := r.Context()
+	user, ok := utility.UserFromContext(ctx)
+	if !ok {
+		logger.New().WithFields(logrus.Fields{
+			"action": "User Not In Context",
+			"status": http.StatusText(http.StatusUnauthorized),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("User Not In Context")
+		response.SendError(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	anime := repository.AnimeRepo(db.DB)
+	result, err := anime.ClearReview(ctx, user.Id, params.ByName("id"))
+	if err == repository.ErrInvalidID {
+		logger.New().WithFields(logrus.Fields{
+			"action": "ObjectID Mongodb Invalid",
+			"status": http.StatusText(http.StatusBadRequest),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn(err.Error())
+		response.SendError(w, r, apperror.ErrAnimeIdInvalid)
+		return
+	}
+
+	if err != nil {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Database Error",
+			"status": http.StatusText(http.StatusInternalServerError),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Error(err.Error())
+		response.SendError(w, r, apperror.ErrInternal.Wrap(err))
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Not Found",
+			"status": http.StatusText(http.StatusNotFound),
+			"path":   r.URL.Path,
+			"method": r.Method,
+		}).Warn("Anime Not Found")
+		response.SendError(w, r, apperror.ErrAnimeNotFound)
+		return
+	}
+
+	res, _ := json.Marshal(response.Msg{Message: i18n.T(lang, "clear review success")})
+
+	logger.New().WithFields(logrus.Fields{
+		"action": "Success",
+		"status": http.StatusText(http.StatusOK),
+		"path":   r.URL.Path,
+		"method": r.Method,
+	}).Info("Request Success")
+	response.SendJSONResponse(w, http.StatusOK, res)
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func CreateIndexes(ctx context.Context, db *mongo.Database) error {
+
+	animeIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "genre_ids", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "tags", Value: 1},
+			},
+		},
+		{
+			// user_id is an equality prefix, so every $text query has to be scoped to an owner
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "name", Value: "text"},
+				{Key: "description", Value: "text"},
+			},
+			Options: options.Index().
+				SetName("anime_text").
+				SetWeights(bson.D{
+					{Key: "name", Value: 10},
+					{Key: "description", Value: 1},
+				}),
+		},
+	}
+
+	_, err := db.Collection("anime").Indexes().CreateMany(ctx, animeIndexes)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	episodeIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "anime_id", Value: 1},
+				{Key: "watched_at", Value: -1},
+			},
+		},
+	}
+
+	_, err = db.Collection("anime_episodes").Indexes().CreateMany(ctx, episodeIndexes)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	// unique indexes are named "<field>_unique", repository relies on it to
+	// report which field a duplicate-key error is about
+	caseInsensitive := &options.Collation{Locale: "en", Strength: 2}
+
+	genreIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().SetName("name_unique").SetUnique(true).SetCollation(caseInsensitive),
+		},
+		{
+			Keys: bson.D{{Key: "parent_id", Value: 1}},
+		},
+	}
+
+	_, err = db.Collection("genre").Indexes().CreateMany(ctx, genreIndexes)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	tagIndexes := []mongo.IndexModel{
+		{
+			// tag names are stored lowercase, so no collation is needed
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "name", Value: 1},
+			},
+			Options: options.Index().SetName("name_unique").SetUnique(true),
+		},
+	}
+
+	_, err = db.Collection("tag").Indexes().CreateMany(ctx, tagIndexes)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	userIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetName("email_unique").SetUnique(true).SetCollation(caseInsensitive),
+		},
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetName("username_unique").SetUnique(true).SetCollation(caseInsensitive),
+		},
+	}
+
+	_, err = db.Collection("users").Indexes().CreateMany(ctx, userIndexes)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"history_anime/src/entity"
+	"history_anime/src/logger"
+	"strings"
+
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrateAnimeStatus rewrites free-form statuses stored before statuses were
+// enumerated ("Watching", "on going", ...) to their entity.AnimeStatus value.
+// Values that cannot be mapped are left untouched.
+func MigrateAnimeStatus(ctx context.Context, db *mongo.Database) error {
+
+	statuses, err := db.Collection("anime").Distinct(ctx, "status", bson.D{})
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	for _, raw := range statuses {
+		value, ok := raw.(string)
+		if !ok || entity.AnimeStatus(value).IsValid() {
+			continue
+		}
+
+		status, ok := entity.ParseAnimeStatus(value)
+		if !ok {
+			continue
+		}
+
+		_, err := db.Collection("anime").UpdateMany(ctx,
+			bson.D{{Key: "status", Value: value}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: status}}}},
+		)
+		if err != nil {
+			return errors.New(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// MigrateAnimeGenres converts the genre name arrays stored before genres were
+// referenced by id into genre_ids. Names without a genre document get one.
+func MigrateAnimeGenres(ctx context.Context, db *mongo.Database) error {
+
+	legacy := bson.D{{Key: "genre", Value: bson.D{{Key: "$exists", Value: true}}}}
+	names, err := db.Collection("anime").Distinct(ctx, "genre", legacy)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	for _, raw := range names {
+		name, ok := raw.(string)
+		if !ok {
+			continue
+		}
+
+		genre := entity.Genre{}
+		opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+		err := db.Collection("genre").FindOne(ctx, bson.D{{Key: "name", Value: name}}, opts).Decode(&genre)
+		if err == mongo.ErrNoDocuments {
+			insert, err := db.Collection("genre").InsertOne(ctx, bson.D{
+				{Key: "name", Value: name},
+				{Key: "created_at", Value: primitive.NewDateTimeFromTime(time.Now())},
+			})
+			if err != nil {
+				return errors.New(err.Error())
+			}
+			genre.Id = insert.InsertedID.(primitive.ObjectID)
+		} else if err != nil {
+			return errors.New(err.Error())
+		}
+
+		_, err = db.Collection("anime").UpdateMany(ctx,
+			bson.D{{Key: "genre", Value: name}},
+			bson.D{{Key: "$addToSet", Value: bson.D{{Key: "genre_ids", Value: genre.Id}}}},
+		)
+		if err != nil {
+			return errors.New(err.Error())
+		}
+	}
+
+	_, err = db.Collection("anime").UpdateMany(ctx, legacy,
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "genre", Value: ""}}}},
+	)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+// MigrateDuplicateGenres merges genres whose names only differ by case into the
+// oldest one, so the unique name index can be built. Anime that referenced a
+// removed duplicate are moved to the kept genre.
+func MigrateDuplicateGenres(ctx context.Context, db *mongo.Database) error {
+
+	pipeline := mongo.Pipeline{
+		{
+			{
+				Key: "$sort",
+				Value: bson.D{
+					{Key: "created_at", Value: 1},
+					{Key: "_id", Value: 1},
+				},
+			},
+		},
+		{
+			{
+				Key: "$group",
+				Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$toLower", Value: "$name"}}},
+					{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+				},
+			},
+		},
+		{
+			{
+				Key: "$match",
+				Value: bson.D{
+					{Key: "ids.1", Value: bson.D{{Key: "$exists", Value: true}}},
+				},
+			},
+		},
+	}
+
+	cur, err := db.Collection("genre").Aggregate(ctx, pipeline)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		group := struct {
+			Ids []primitive.ObjectID `bson:"ids"`
+		}{}
+		err := cur.Decode(&group)
+		if err != nil {
+			return errors.New(err.Error())
+		}
+
+		keep := group.Ids[0]
+		duplicates := group.Ids[1:]
+		used := bson.D{{Key: "genre_ids", Value: bson.D{{Key: "$in", Value: duplicates}}}}
+
+		_, err = db.Collection("anime").UpdateMany(ctx, used,
+			bson.D{{Key: "$addToSet", Value: bson.D{{Key: "genre_ids", Value: keep}}}},
+		)
+		if err != nil {
+			return errors.New(err.Error())
+		}
+
+		_, err = db.Collection("anime").UpdateMany(ctx, used,
+			bson.D{{Key: "$pull", Value: bson.D{{Key: "genre_ids", Value: bson.D{{Key: "$in", Value: duplicates}}}}}},
+		)
+		if err != nil {
+			return errors.New(err.Error())
+		}
+
+		_, err = db.Collection("genre").DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: duplicates}}}})
+		if err != nil {
+			return errors.New(err.Error())
+		}
+	}
+
+	return nil
+}
+
+// MigrateAnimeOwner handles anime stored before entries belonged to a user.
+// Nobody can list, edit or delete them, so they are given to owner, the user
+// id set in ANIME_DEFAULT_OWNER. Without an owner they are only counted and
+// logged.
+func MigrateAnimeOwner(ctx context.Context, db *mongo.Database, owner string) error {
+
+	// matches a missing user_id as well as null
+	ownerless := bson.D{{Key: "user_id", Value: nil}}
+	count, err := db.Collection("anime").CountDocuments(ctx, ownerless)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	if owner == "" {
+		logger.New().WithFields(logrus.Fields{
+			"action": "Anime Without Owner",
+			"count":  count,
+		}).Warn("set ANIME_DEFAULT_OWNER to a user id to assign them")
+		return nil
+	}
+
+	ownerID, err := primitive.ObjectIDFromHex(owner)
+	if err != nil {
+		return fmt.Errorf("ANIME_DEFAULT_OWNER %q is not a user id", owner)
+	}
+
+	err = db.Collection("users").FindOne(ctx, bson.D{{Key: "_id", Value: ownerID}}).Err()
+	if err == mongo.ErrNoDocuments {
+		return fmt.Errorf("ANIME_DEFAULT_OWNER %q: user not found", owner)
+	} else if err != nil {
+		return errors.New(err.Error())
+	}
+
+	_, err = db.Collection("anime").UpdateMany(ctx, ownerless,
+		bson.D{{Key: "$set", Value: bson.D{{Key: "user_id", Value: ownerID}}}},
+	)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+// MigrateDuplicateUsers checks that no two users share an email or username
+// once case is ignored, which the unique user indexes would otherwise fail on.
+// Accounts are not merged automatically since they may belong to different
+// people, so the conflicts are returned for an operator to resolve.
+func MigrateDuplicateUsers(ctx context.Context, db *mongo.Database) error {
+
+	conflicts := []string{}
+	for _, field := range []string{"email", "username"} {
+		duplicates, err := duplicateUsers(ctx, db, field)
+		if err != nil {
+			return err
+		}
+		conflicts = append(conflicts, duplicates...)
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("duplicate users, resolve them before the unique indexes can be built: %s", strings.Join(conflicts, "; "))
+	}
+
+	return nil
+}
+
+// duplicateUsers groups users by field with the collation of its unique index
+// and describes every group holding more than one user.
+func duplicateUsers(ctx context.Context, db *mongo.Database, field string) ([]string, error) {
+
+	pipeline := mongo.Pipeline{
+		{
+			{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}},
+		},
+		{
+			{
+				Key: "$group",
+				Value: bson.D{
+					{Key: "_id", Value: "$" + field},
+					{Key: "values", Value: bson.D{{Key: "$push", Value: "$" + field}}},
+					{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+				},
+			},
+		},
+		{
+			{
+				Key: "$match",
+				Value: bson.D{
+					{Key: "ids.1", Value: bson.D{{Key: "$exists", Value: true}}},
+				},
+			},
+		},
+		{
+			{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}},
+		},
+	}
+
+	opts := options.Aggregate().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+	cur, err := db.Collection("users").Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	conflicts := []string{}
+	for cur.Next(ctx) {
+		group := struct {
+			Values []string             `bson:"values"`
+			Ids    []primitive.ObjectID `bson:"ids"`
+		}{}
+		err := cur.Decode(&group)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		users := []string{}
+		for i, id := range group.Ids {
+			users = append(users, fmt.Sprintf("%s (%s)", group.Values[i], id.Hex()))
+		}
+		conflicts = append(conflicts, field+" "+strings.Join(users, ", "))
+	}
+
+	return conflicts, nil
+}
@@ -27,6 +27,37 @@ func CreateConnection(ctx context.Context) {
 	}
 
 	db := client.Database("history-anime")
+	err = MigrateAnimeStatus(ctx, db)
+	if err != nil {
+		panic(err)
+	}
+
+	err = MigrateAnimeGenres(ctx, db)
+	if err != nil {
+		panic(err)
+	}
+
+	err = MigrateDuplicateGenres(ctx, db)
+	if err != nil {
+		panic(err)
+	}
+
+	err = MigrateAnimeOwner(ctx, db, os.Getenv("ANIME_DEFAULT_OWNER"))
+	if err != nil {
+		panic(err)
+	}
+
+	err = MigrateDuplicateUsers(ctx, db)
+	if err != nil {
+		panic(err)
+	}
+
+	// indexes come last, the unique ones need the migrated data
+	err = CreateIndexes(ctx, db)
+	if err != nil {
+		panic(err)
+	}
+
 	DB = db
 	fmt.Println("database terhubung")
 }
@@ -7,11 +7,47 @@ import (
 )
 
 type Anime struct {
-	Id          primitive.ObjectID `bson:"_id" json:"_id"`
-	Name        string             `bson:"name" json:"name"`
-	Genre       []string           `bson:"genre" json:"genre"`
-	Description string             `bson:"description" json:"description"`
-	Image       string             `bson:"image" json:"image"`
-	Status      string             `bson:"status" json:"status"`
-	Created_at  time.Time          `bson:"created_at" json:"created_at"`
+	Id              primitive.ObjectID   `bson:"_id" json:"_id"`
+	UserId          primitive.ObjectID   `bson:"user_id" json:"user_id"`
+	Name            string               `bson:"name" json:"name"`
+	GenreIds        []primitive.ObjectID `bson:"genre_ids" json:"genre_ids"`
+	Genre           []string             `bson:"genre" json:"genre"`
+	Tags            []string             `bson:"tags,omitempty" json:"tags"`
+	Description     string               `bson:"description" json:"description"`
+	Image           string               `bson:"image" json:"image"`
+	Status          AnimeStatus          `bson:"status" json:"status"`
+	TotalEpisodes   int                  `bson:"total_episodes" json:"total_episodes"`
+	WatchedEpisodes int                  `bson:"watched_episodes" json:"watched_episodes"`
+	Score           *float64             `bson:"score,omitempty" json:"score,omitempty"`
+	Review          string               `bson:"review,omitempty" json:"review,omitempty"`
+	Reviewed_at     *time.Time           `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	Started_at      *time.Time           `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	Completed_at    *time.Time           `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	Created_at      time.Time            `bson:"created_at" json:"created_at"`
+	Updated_at      time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+type AnimeSearch struct {
+	Anime     `bson:",inline"`
+	Relevance float64        `bson:"relevance" json:"relevance"`
+	Highlight AnimeHighlight `bson:"-" json:"highlight"`
+}
+
+type AnimeHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+const (
+	BulkSuccess = "success"
+	BulkError   = "error"
+	BulkSkipped = "skipped"
+)
+
+type AnimeBulkResult struct {
+	Index  int         `json:"index"`
+	Op     string      `json:"op"`
+	Id     string      `json:"_id,omitempty"`
+	Status string      `json:"status"`
+	Errors FieldErrors `json:"errors,omitempty"`
 }
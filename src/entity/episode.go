@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Episode struct {
+	Id         primitive.ObjectID `bson:"_id" json:"_id"`
+	AnimeId    primitive.ObjectID `bson:"anime_id" json:"anime_id"`
+	UserId     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Episode    int                `bson:"episode" json:"episode"`
+	Note       string             `bson:"note" json:"note"`
+	Watched_at time.Time          `bson:"watched_at" json:"watched_at"`
+	Created_at time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -7,7 +7,19 @@ import (
 )
 
 type Genre struct {
-	Id         primitive.ObjectID `bson:"_id" json:"_id"`
-	Name       string             `bson:"name" json:"name"`
-	Created_at time.Time          `bson:"created_at" json:"created_at"`
+	Id         primitive.ObjectID  `bson:"_id" json:"_id"`
+	Name       string              `bson:"name" json:"name"`
+	ParentId   *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Created_by primitive.ObjectID  `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	Created_at time.Time           `bson:"created_at" json:"created_at"`
+	Updated_by primitive.ObjectID  `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	Updated_at *time.Time          `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+type GenreStats struct {
+	Id           primitive.ObjectID `bson:"_id" json:"_id"`
+	Name         string             `bson:"name" json:"name"`
+	Total        int64              `bson:"total" json:"total"`
+	Status       map[string]int64   `bson:"status" json:"status"`
+	AverageScore *float64           `bson:"average_score" json:"average_score"`
 }
@@ -0,0 +1,20 @@
+package entity
+
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+)
+
+// ImportRow reports what happened to one entry of an import. Reason is a
+// message catalogue key, formatted with ReasonArgs once translated, Errors
+// holds the field errors of a row skipped for invalid data.
+type ImportRow struct {
+	Row        int           `json:"row"`
+	Title      string        `json:"title"`
+	Result     string        `json:"result"`
+	Id         string        `json:"_id,omitempty"`
+	Reason     string        `json:"reason,omitempty"`
+	ReasonArgs []interface{} `json:"-"`
+	Errors     FieldErrors   `json:"errors,omitempty"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type Stats struct {
+	Total           int64            `json:"total"`
+	Status          map[string]int64 `json:"status"`
+	AddedPerMonth   []StatsPeriod    `json:"added_per_month"`
+	EpisodesPerWeek []StatsPeriod    `json:"episodes_per_week"`
+	TopGenres       []StatsGenre     `json:"top_genres"`
+	CompletionRate  float64          `json:"completion_rate"`
+}
+
+type StatsPeriod struct {
+	Period string `bson:"_id" json:"period"`
+	Count  int64  `bson:"count" json:"count"`
+}
+
+type StatsGenre struct {
+	Id    primitive.ObjectID `bson:"_id" json:"_id"`
+	Name  string             `bson:"name" json:"name"`
+	Count int64              `bson:"count" json:"count"`
+}
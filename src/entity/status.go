@@ -0,0 +1,127 @@
+package entity
+
+import "strings"
+
+type AnimeStatus string
+
+const (
+	StatusPlanToWatch AnimeStatus = "plan-to-watch"
+	StatusWatching    AnimeStatus = "watching"
+	StatusOnHold      AnimeStatus = "on-hold"
+	StatusDropped     AnimeStatus = "dropped"
+	StatusCompleted   AnimeStatus = "completed"
+	StatusRewatching  AnimeStatus = "rewatching"
+)
+
+var AnimeStatuses = []AnimeStatus{
+	StatusPlanToWatch,
+	StatusWatching,
+	StatusOnHold,
+	StatusDropped,
+	StatusCompleted,
+	StatusRewatching,
+}
+
+var animeStatusTransitions = map[AnimeStatus][]AnimeStatus{
+	StatusPlanToWatch: {StatusWatching, StatusDropped, StatusCompleted},
+	StatusWatching:    {StatusOnHold, StatusDropped, StatusCompleted},
+	StatusOnHold:      {StatusWatching, StatusDropped},
+	StatusDropped:     {StatusPlanToWatch, StatusWatching},
+	StatusCompleted:   {StatusRewatching},
+	StatusRewatching:  {StatusOnHold, StatusDropped, StatusCompleted},
+}
+
+var animeStatusAliases = map[string]AnimeStatus{
+	"plan to watch": StatusPlanToWatch,
+	"plantowatch":   StatusPlanToWatch,
+	"planned":       StatusPlanToWatch,
+	"ptw":           StatusPlanToWatch,
+	"on going":      StatusWatching,
+	"ongoing":       StatusWatching,
+	"on hold":       StatusOnHold,
+	"onhold":        StatusOnHold,
+	"paused":        StatusOnHold,
+	"drop":          StatusDropped,
+	"complete":      StatusCompleted,
+	"finish":        StatusCompleted,
+	"finished":      StatusCompleted,
+	"done":          StatusCompleted,
+	"rewatch":       StatusRewatching,
+	"re-watching":   StatusRewatching,
+}
+
+func (status AnimeStatus) IsValid() bool {
+	_, ok := animeStatusTransitions[status]
+	return ok
+}
+
+// NextStatuses lists the statuses an entry may move to from status.
+func (status AnimeStatus) NextStatuses() []AnimeStatus {
+	return animeStatusTransitions[status]
+}
+
+// CanTransitionTo reports whether an entry may move from status to next.
+// Keeping the same status is always allowed, and entries still holding a
+// legacy free-form value may move to any valid status.
+func (status AnimeStatus) CanTransitionTo(next AnimeStatus) bool {
+
+	if status == next {
+		return true
+	}
+
+	if !next.IsValid() {
+		return false
+	}
+
+	if !status.IsValid() {
+		return true
+	}
+
+	for _, allowed := range animeStatusTransitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ForProgress returns the status an entry should have once watched of total
+// episodes are watched: watching the last episode completes it and going back
+// below the total on a completed entry means it is being rewatched. The status
+// is kept without a known total, or when the transitions don't allow the move,
+// e.g. from on-hold straight to completed.
+func (status AnimeStatus) ForProgress(watched int, total int) AnimeStatus {
+
+	if total <= 0 {
+		return status
+	}
+
+	next := status
+	if watched == total {
+		next = StatusCompleted
+	} else if status == StatusCompleted && watched < total {
+		next = StatusRewatching
+	}
+
+	if !status.CanTransitionTo(next) {
+		return status
+	}
+
+	return next
+}
+
+// ParseAnimeStatus maps the free-form values stored before statuses were
+// enumerated ("Watching", "on going", ...) onto an AnimeStatus.
+func ParseAnimeStatus(raw string) (AnimeStatus, bool) {
+
+	normalized := strings.ToLower(strings.Join(strings.Fields(raw), " "))
+
+	status := AnimeStatus(strings.ReplaceAll(strings.ReplaceAll(normalized, " ", "-"), "_", "-"))
+	if status.IsValid() {
+		return status, true
+	}
+
+	status, ok := animeStatusAliases[normalized]
+	return status, ok
+}
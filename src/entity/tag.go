@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Tag struct {
+	Id         primitive.ObjectID `bson:"_id" json:"_id"`
+	UserId     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name       string             `bson:"name" json:"name"`
+	Created_at time.Time          `bson:"created_at" json:"created_at"`
+}
@@ -7,4 +7,5 @@ type Users struct {
 	Username string             `bson:"username"`
 	Email    string             `bson:"email"`
 	Password string             `bson:"password"`
+	Language string             `bson:"language,omitempty"`
 }
@@ -0,0 +1,42 @@
+package entity
+
+import "strings"
+
+// FieldError describes one failed validation rule. Field is the json path of
+// the value, Rule the validator tag (or check) that failed and Param its
+// argument, if any. Messages holds Message in every supported language.
+type FieldError struct {
+	Field    string            `json:"field"`
+	Rule     string            `json:"rule"`
+	Param    string            `json:"param"`
+	Message  string            `json:"message"`
+	Messages map[string]string `json:"-"`
+}
+
+type FieldErrors []FieldError
+
+// Localize returns a copy with Message in lang, falling back to the current
+// message when there is no translation.
+func (errs FieldErrors) Localize(lang string) FieldErrors {
+
+	localized := make(FieldErrors, len(errs))
+	for i, err := range errs {
+		if message, ok := err.Messages[lang]; ok {
+			err.Message = message
+		}
+		localized[i] = err
+	}
+
+	return localized
+}
+
+// String joins the messages, for logging.
+func (errs FieldErrors) String() string {
+
+	messages := []string{}
+	for _, err := range errs {
+		messages = append(messages, err.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"history_anime/src/utility"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Languages are the languages with a catalogue in locales, the first one is
+// the default.
+var Languages = []string{"en", "id"}
+
+//go:embed locales/*.json templates
+var files embed.FS
+
+// Catalogue is the content of locales/<lang>.json. Messages are keyed by
+// their English text so untranslated strings, like driver errors, pass
+// through unchanged. The validation sections use universal-translator
+// placeholders, {0} is always the field.
+type Catalogue struct {
+	Messages         map[string]string `json:"messages"`
+	ValidationTags   map[string]string `json:"validation_tags"`
+	ValidationChecks map[string]string `json:"validation_checks"`
+}
+
+var catalogues = map[string]Catalogue{}
+
+var templates = map[string]*template.Template{}
+
+func init() {
+
+	for _, lang := range Languages {
+		data, err := files.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(err)
+		}
+
+		catalogue := Catalogue{}
+		err = json.Unmarshal(data, &catalogue)
+		if err != nil {
+			panic(fmt.Sprintf("locales/%s.json: %s", lang, err))
+		}
+		catalogues[lang] = catalogue
+
+		templates[lang] = template.Must(template.ParseFS(files, "templates/"+lang+"/*.html"))
+	}
+}
+
+// Supported reports whether lang has a catalogue.
+func Supported(lang string) bool {
+	_, ok := catalogues[lang]
+	return ok
+}
+
+// Get returns the catalogue of lang, or of the default language.
+func Get(lang string) Catalogue {
+
+	if catalogue, ok := catalogues[lang]; ok {
+		return catalogue
+	}
+
+	return catalogues[Languages[0]]
+}
+
+// T translates message to lang and formats it with args, if any.
+func T(lang string, message string, args ...interface{}) string {
+
+	if translated, ok := Get(lang).Messages[message]; ok {
+		message = translated
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+
+	return message
+}
+
+// Language picks the language of the response: the logged in user's
+// preference, then the first supported language in Accept-Language, then the
+// default.
+func Language(r *http.Request) string {
+
+	if user, ok := utility.UserFromContext(r.Context()); ok && Supported(user.Language) {
+		return user.Language
+	}
+
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.Split(part, ";")[0]))
+		tag = strings.Split(tag, "-")[0]
+		if Supported(tag) {
+			return tag
+		}
+	}
+
+	return Languages[0]
+}
+
+// Email renders templates/<lang>/<name>.html with data.
+func Email(lang string, name string, data interface{}) (string, error) {
+
+	tmpl, ok := templates[lang]
+	if !ok {
+		tmpl = templates[Languages[0]]
+	}
+
+	html := bytes.Buffer{}
+	err := tmpl.ExecuteTemplate(&html, name+".html", data)
+	if err != nil {
+		return "", err
+	}
+
+	return html.String(), nil
+}
@@ -1,8 +1,8 @@
 package middlewares
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
+	"history_anime/src/apperror"
 	"history_anime/src/db"
 	"history_anime/src/entity"
 	"history_anime/src/logger"
@@ -12,11 +12,13 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func OnlyLogin(next httprouter.Handle) httprouter.Handle {
@@ -25,84 +27,69 @@ func OnlyLogin(next httprouter.Handle) httprouter.Handle {
 		token, err := r.Cookie("token")
 		if err == http.ErrNoCookie {
 
-			res, _ := json.Marshal(response.Errors{
-				Errors: []string{"Unauthorized"},
-			})
-
 			logger.New().WithFields(logrus.Fields{
 				"action": "No Token",
 				"status": http.StatusText(http.StatusUnauthorized),
 				"path":   r.URL.Path,
 				"method": r.Method,
 			}).Warn(err.Error())
-			response.SendJSONResponse(w, http.StatusUnauthorized, res)
+			response.SendError(w, r, apperror.ErrUnauthorized)
 			return
 		}
 
 		id, err := utility.VerifyToken(os.Getenv("SECRET_KEY"), token.Value)
 		if err != nil {
-			res, _ := json.Marshal(response.Errors{
-				Errors: []string{"Unauthorized"},
-			})
-
 			logger.New().WithFields(logrus.Fields{
 				"action": "Token Invalid",
 				"status": http.StatusText(http.StatusUnauthorized),
 				"path":   r.URL.Path,
 				"method": r.Method,
 			}).Warn(err.Error())
-			response.SendJSONResponse(w, http.StatusUnauthorized, res)
+			appErr := apperror.ErrUnauthorized
+			if errors.Is(err, jwt.ErrTokenExpired) {
+				appErr = apperror.ErrTokenExpired
+			}
+			response.SendError(w, r, appErr.Wrap(err))
 			return
 		}
 
 		objId, err := primitive.ObjectIDFromHex(id)
 		if err != nil {
-			res, _ := json.Marshal(response.Errors{
-				Errors: []string{"Unauthorized"},
-			})
-
 			logger.New().WithFields(logrus.Fields{
 				"action": "ObjectID Mongodb Invalid",
 				"status": http.StatusText(http.StatusUnauthorized),
 				"path":   r.URL.Path,
 				"method": r.Method,
 			}).Warn(err.Error())
-			response.SendJSONResponse(w, http.StatusUnauthorized, res)
+			response.SendError(w, r, apperror.ErrUnauthorized)
 			return
 		}
 
-		ctx := context.Background()
+		ctx := r.Context()
 		filter := bson.D{{Key: "_id", Value: objId}}
+		projection := options.FindOne().SetProjection(bson.D{{Key: "password", Value: 0}})
 		result := entity.Users{}
-		err = db.DB.Collection("users").FindOne(ctx, filter).Decode(&result)
+		err = db.DB.Collection("users").FindOne(ctx, filter, projection).Decode(&result)
 		if err == mongo.ErrNoDocuments {
-			res, _ := json.Marshal(response.Errors{
-				Errors: []string{"Unauthorized"},
-			})
-
 			logger.New().WithFields(logrus.Fields{
 				"action": "User Not Found",
 				"status": http.StatusText(http.StatusUnauthorized),
 				"path":   r.URL.Path,
 				"method": r.Method,
 			}).Warn(err.Error())
-			response.SendJSONResponse(w, http.StatusUnauthorized, res)
+			response.SendError(w, r, apperror.ErrUnauthorized)
 			return
 		} else if err != nil {
-			res, _ := json.Marshal(response.Errors{
-				Errors: []string{err.Error()},
-			})
-
 			logger.New().WithFields(logrus.Fields{
 				"action": "Database Error",
 				"status": http.StatusText(http.StatusInternalServerError),
 				"path":   r.URL.Path,
 				"method": r.Method,
 			}).Error(err.Error())
-			response.SendJSONResponse(w, http.StatusInternalServerError, res)
+			response.SendError(w, r, apperror.ErrInternal.Wrap(err))
 			return
 		}
 
-		next(w, r, params)
+		next(w, r.WithContext(utility.WithUser(ctx, result)), params)
 	}
 }
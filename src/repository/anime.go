@@ -2,38 +2,60 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"history_anime/src/entity"
 	"history_anime/src/requestbody"
+	"strconv"
+	"strings"
 
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type animeRepoInterface interface {
-	Add(ctx context.Context, body *requestbody.Anime) (string, error)
-	Update(ctx context.Context, body *requestbody.Anime, id string) (*mongo.UpdateResult, error)
-	Del(ctx context.Context, id string) (*mongo.DeleteResult, error)
-	GetAll(ctx context.Context) ([]entity.Anime, error)
+	Add(ctx context.Context, userID primitive.ObjectID, body *requestbody.Anime) (string, error)
+	Update(ctx context.Context, userID primitive.ObjectID, body *requestbody.Anime, current *entity.Anime) (*mongo.UpdateResult, error)
+	Patch(ctx context.Context, userID primitive.ObjectID, body *requestbody.Anime, fields []string, current *entity.Anime) (*mongo.UpdateResult, error)
+	SetProgress(ctx context.Context, userID primitive.ObjectID, current *entity.Anime, watched int, status entity.AnimeStatus) (*mongo.UpdateResult, error)
+	SetReview(ctx context.Context, userID primitive.ObjectID, body *requestbody.Review, id string) (*mongo.UpdateResult, error)
+	ClearReview(ctx context.Context, userID primitive.ObjectID, id string) (*mongo.UpdateResult, error)
+	Del(ctx context.Context, userID primitive.ObjectID, id string) (*mongo.DeleteResult, error)
+	GetByID(ctx context.Context, userID primitive.ObjectID, id string) (*entity.Anime, error)
+	GetAll(ctx context.Context, userID primitive.ObjectID, query *requestbody.AnimeQuery) ([]entity.Anime, int64, string, error)
+	Search(ctx context.Context, userID primitive.ObjectID, query *requestbody.AnimeSearch) ([]entity.AnimeSearch, error)
+	Export(ctx context.Context, userID primitive.ObjectID) (*mongo.Cursor, error)
+	GetMany(ctx context.Context, userID primitive.ObjectID, ids []primitive.ObjectID) (map[primitive.ObjectID]entity.Anime, error)
+	Bulk(ctx context.Context, userID primitive.ObjectID, writes []AnimeBulkWrite, atomic bool) ([]entity.AnimeBulkResult, error)
+}
+
+// AnimeBulkWrite is one validated operation of a bulk request. Current is the
+// stored entry for update and delete.
+type AnimeBulkWrite struct {
+	Index   int
+	Op      string
+	Body    *requestbody.Anime
+	Current *entity.Anime
 }
 
 type animeRepo struct {
 	DB *mongo.Database
 }
 
-func (anime *animeRepo) Add(ctx context.Context, body *requestbody.Anime) (string, error) {
+func (anime *animeRepo) Add(ctx context.Context, userID primitive.ObjectID, body *requestbody.Anime) (string, error) {
 
-	insertDoc := bson.D{
-		{Key: "name", Value: body.Name},
-		{Key: "image", Value: body.Image},
-		{Key: "genre", Value: body.Genre},
-		{Key: "description", Value: body.Description},
-		{Key: "status", Value: body.Status},
-		{Key: "created_at", Value: primitive.NewDateTimeFromTime(time.Now())},
+	genreIDs, err := objectIDs(body.GenreIds)
+	if err != nil {
+		return "", err
 	}
+
+	insertDoc := animeInsertDoc(userID, body, genreIDs, primitive.NewDateTimeFromTime(time.Now()))
+
 	insert, err := anime.DB.Collection("anime").InsertOne(ctx, insertDoc)
 	if err != nil {
 		return "", errors.New(err.Error())
@@ -47,24 +69,103 @@ func (anime *animeRepo) Add(ctx context.Context, body *requestbody.Anime) (strin
 	return insertID.Hex(), nil
 }
 
-func (anime *animeRepo) Update(ctx context.Context, body *requestbody.Anime, id string) (*mongo.UpdateResult, error) {
+func (anime *animeRepo) Update(ctx context.Context, userID primitive.ObjectID, body *requestbody.Anime, current *entity.Anime) (*mongo.UpdateResult, error) {
 
-	upDoc := bson.D{
-		{Key: "$set", Value: bson.D{
-			{Key: "name", Value: body.Name},
-			{Key: "image", Value: body.Image},
-			{Key: "genre", Value: body.Genre},
-			{Key: "description", Value: body.Description},
-			{Key: "status", Value: body.Status},
-		}},
+	genreIDs, err := objectIDs(body.GenreIds)
+	if err != nil {
+		return nil, err
 	}
 
-	objId, err := primitive.ObjectIDFromHex(id)
+	setDoc := animeSetDoc(body, genreIDs, current, primitive.NewDateTimeFromTime(time.Now()))
+
+	filter := bson.D{
+		{Key: "_id", Value: current.Id},
+		{Key: "user_id", Value: userID},
+	}
+
+	up, err := anime.DB.Collection("anime").UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: setDoc}})
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
 
-	up, err := anime.DB.Collection("anime").UpdateByID(ctx, objId, upDoc)
+	return up, nil
+}
+
+func animeInsertDoc(userID primitive.ObjectID, body *requestbody.Anime, genreIDs []primitive.ObjectID, now primitive.DateTime) bson.D {
+
+	insertDoc := bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "name", Value: body.Name},
+		{Key: "image", Value: body.Image},
+		{Key: "genre_ids", Value: genreIDs},
+		{Key: "tags", Value: normalizeTags(body.Tags)},
+		{Key: "description", Value: body.Description},
+		{Key: "status", Value: body.Status},
+		{Key: "total_episodes", Value: body.TotalEpisodes},
+		{Key: "watched_episodes", Value: body.WatchedEpisodes},
+		{Key: "created_at", Value: now},
+		{Key: "updated_at", Value: now},
+	}
+
+	return append(insertDoc, statusTimestamps(nil, entity.AnimeStatus(body.Status), now)...)
+}
+
+func animeSetDoc(body *requestbody.Anime, genreIDs []primitive.ObjectID, current *entity.Anime, now primitive.DateTime) bson.D {
+
+	setDoc := bson.D{
+		{Key: "name", Value: body.Name},
+		{Key: "image", Value: body.Image},
+		{Key: "genre_ids", Value: genreIDs},
+		{Key: "tags", Value: normalizeTags(body.Tags)},
+		{Key: "description", Value: body.Description},
+		{Key: "status", Value: body.Status},
+		{Key: "total_episodes", Value: body.TotalEpisodes},
+		{Key: "watched_episodes", Value: body.WatchedEpisodes},
+		{Key: "updated_at", Value: now},
+	}
+
+	return append(setDoc, statusTimestamps(current, entity.AnimeStatus(body.Status), now)...)
+}
+
+func (anime *animeRepo) Patch(ctx context.Context, userID primitive.ObjectID, body *requestbody.Anime, fields []string, current *entity.Anime) (*mongo.UpdateResult, error) {
+
+	genreIDs, err := objectIDs(body.GenreIds)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{
+		"name":             body.Name,
+		"image":            body.Image,
+		"genre_ids":        genreIDs,
+		"tags":             normalizeTags(body.Tags),
+		"description":      body.Description,
+		"status":           body.Status,
+		"total_episodes":   body.TotalEpisodes,
+		"watched_episodes": body.WatchedEpisodes,
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	setDoc := bson.D{}
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			continue
+		}
+		setDoc = append(setDoc, bson.E{Key: field, Value: value})
+
+		if field == "status" {
+			setDoc = append(setDoc, statusTimestamps(current, entity.AnimeStatus(body.Status), now)...)
+		}
+	}
+	setDoc = append(setDoc, bson.E{Key: "updated_at", Value: now})
+
+	filter := bson.D{
+		{Key: "_id", Value: current.Id},
+		{Key: "user_id", Value: userID},
+	}
+
+	up, err := anime.DB.Collection("anime").UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: setDoc}})
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -72,54 +173,546 @@ func (anime *animeRepo) Update(ctx context.Context, body *requestbody.Anime, id
 	return up, nil
 }
 
-func (anime *animeRepo) Del(ctx context.Context, id string) (*mongo.DeleteResult, error) {
+// SetProgress only matches while watched_episodes still holds the value the
+// caller read, so concurrent increments cannot overwrite each other.
+func (anime *animeRepo) SetProgress(ctx context.Context, userID primitive.ObjectID, current *entity.Anime, watched int, status entity.AnimeStatus) (*mongo.UpdateResult, error) {
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	setDoc := bson.D{
+		{Key: "watched_episodes", Value: watched},
+		{Key: "status", Value: status},
+		{Key: "updated_at", Value: now},
+	}
+	setDoc = append(setDoc, statusTimestamps(current, status, now)...)
+
+	var currentWatched interface{} = current.WatchedEpisodes
+	if current.WatchedEpisodes == 0 {
+		// entries created before progress tracking have no watched_episodes field
+		currentWatched = bson.D{{Key: "$in", Value: bson.A{0, nil}}}
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: current.Id},
+		{Key: "user_id", Value: userID},
+		{Key: "watched_episodes", Value: currentWatched},
+	}
+
+	up, err := anime.DB.Collection("anime").UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: setDoc}})
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return up, nil
+}
+
+// SetReview replaces the score and review; a missing one is removed so PUT
+// always leaves exactly what was sent.
+func (anime *animeRepo) SetReview(ctx context.Context, userID primitive.ObjectID, body *requestbody.Review, id string) (*mongo.UpdateResult, error) {
 
 	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	setDoc := bson.D{
+		{Key: "reviewed_at", Value: now},
+		{Key: "updated_at", Value: now},
+	}
+	unsetDoc := bson.D{}
+
+	if body.Score != nil {
+		setDoc = append(setDoc, bson.E{Key: "score", Value: *body.Score})
+	} else {
+		unsetDoc = append(unsetDoc, bson.E{Key: "score", Value: ""})
+	}
+
+	if body.Review != "" {
+		setDoc = append(setDoc, bson.E{Key: "review", Value: body.Review})
+	} else {
+		unsetDoc = append(unsetDoc, bson.E{Key: "review", Value: ""})
+	}
+
+	updateDoc := bson.D{{Key: "$set", Value: setDoc}}
+	if len(unsetDoc) > 0 {
+		updateDoc = append(updateDoc, bson.E{Key: "$unset", Value: unsetDoc})
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objId},
+		{Key: "user_id", Value: userID},
+	}
+
+	up, err := anime.DB.Collection("anime").UpdateOne(ctx, filter, updateDoc)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return up, nil
+}
+
+func (anime *animeRepo) ClearReview(ctx context.Context, userID primitive.ObjectID, id string) (*mongo.UpdateResult, error) {
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	updateDoc := bson.D{
+		{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "updated_at", Value: primitive.NewDateTimeFromTime(time.Now())},
+			},
+		},
+		{
+			Key: "$unset",
+			Value: bson.D{
+				{Key: "score", Value: ""},
+				{Key: "review", Value: ""},
+				{Key: "reviewed_at", Value: ""},
+			},
+		},
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objId},
+		{Key: "user_id", Value: userID},
+	}
+
+	up, err := anime.DB.Collection("anime").UpdateOne(ctx, filter, updateDoc)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
 
+	return up, nil
+}
+
+// statusTimestamps returns the fields to set when an entry moves to next:
+// started_at the first time it is watched, completed_at whenever it is completed.
+func statusTimestamps(current *entity.Anime, next entity.AnimeStatus, now primitive.DateTime) bson.D {
+
+	timestamps := bson.D{}
+	if current != nil && current.Status == next {
+		return timestamps
+	}
+
+	if (next == entity.StatusWatching || next == entity.StatusRewatching) && (current == nil || current.Started_at == nil) {
+		timestamps = append(timestamps, bson.E{Key: "started_at", Value: now})
+	}
+
+	if next == entity.StatusCompleted {
+		timestamps = append(timestamps, bson.E{Key: "completed_at", Value: now})
+	}
+
+	return timestamps
+}
+
+func (anime *animeRepo) Del(ctx context.Context, userID primitive.ObjectID, id string) (*mongo.DeleteResult, error) {
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
 	filter := bson.D{
 		{
 			Key:   "_id",
 			Value: objId,
 		},
+		{
+			Key:   "user_id",
+			Value: userID,
+		},
 	}
 	result, err := anime.DB.Collection("anime").DeleteOne(ctx, filter)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
 
+	if result.DeletedCount > 0 {
+		episodeFilter := bson.D{
+			{Key: "anime_id", Value: objId},
+			{Key: "user_id", Value: userID},
+		}
+		_, err = anime.DB.Collection("anime_episodes").DeleteMany(ctx, episodeFilter)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+	}
+
 	return result, nil
 }
 
-func (anime *animeRepo) GetAll(ctx context.Context) ([]entity.Anime, error) {
+func (anime *animeRepo) GetByID(ctx context.Context, userID primitive.ObjectID, id string) (*entity.Anime, error) {
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objId},
+		{Key: "user_id", Value: userID},
+	}
 
-	sortDoc := bson.D{
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	pipeline = append(pipeline, genreLookup()...)
+
+	cur, err := anime.DB.Collection("anime").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	if !cur.Next(ctx) {
+		if cur.Err() != nil {
+			return nil, errors.New(cur.Err().Error())
+		}
+		return nil, mongo.ErrNoDocuments
+	}
+
+	result := entity.Anime{}
+	err = cur.Decode(&result)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &result, nil
+}
+
+// genreLookup resolves genre_ids into the genre names returned to clients.
+func genreLookup() mongo.Pipeline {
+
+	return mongo.Pipeline{
 		{
-			Key: "$sort",
+			{
+				Key: "$lookup",
+				Value: bson.D{
+					{Key: "from", Value: "genre"},
+					{Key: "localField", Value: "genre_ids"},
+					{Key: "foreignField", Value: "_id"},
+					{Key: "as", Value: "genre_docs"},
+				},
+			},
+		},
+		{
+			{
+				Key: "$addFields",
+				Value: bson.D{
+					{Key: "genre", Value: "$genre_docs.name"},
+				},
+			},
+		},
+		{
+			{
+				Key:   "$unset",
+				Value: "genre_docs",
+			},
+		},
+	}
+}
+
+func objectIDs(ids []string) ([]primitive.ObjectID, error) {
+
+	result := []primitive.ObjectID{}
+	for _, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, ErrInvalidID
+		}
+		result = append(result, objID)
+	}
+
+	return result, nil
+}
+
+func (anime *animeRepo) GetAll(ctx context.Context, userID primitive.ObjectID, query *requestbody.AnimeQuery) ([]entity.Anime, int64, string, error) {
+
+	filter := bson.D{
+		{
+			Key:   "user_id",
+			Value: userID,
+		},
+	}
+	if query.Status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: query.Status})
+	}
+	if query.Genre != "" {
+		genres, err := genreFilter(ctx, anime.DB, query.Genre)
+		if err != nil {
+			return []entity.Anime{}, 0, "", err
+		}
+		filter = append(filter, genres)
+	}
+	if query.Tag != "" {
+		filter = append(filter, bson.E{Key: "tags", Value: strings.ToLower(strings.TrimSpace(query.Tag))})
+	}
+
+	direction := -1
+	comparison := "$lt"
+	if query.Order == "asc" {
+		direction = 1
+		comparison = "$gt"
+	}
+
+	sortField := query.Sort
+	dataPipeline := bson.A{}
+	if query.Sort == "score" {
+		// unrated entries sort as 0 so they stay below every real score
+		sortField = "score_sort"
+		dataPipeline = append(dataPipeline, bson.D{
+			{
+				Key: "$addFields",
+				Value: bson.D{
+					{Key: sortField, Value: bson.D{{Key: "$ifNull", Value: bson.A{"$score", 0}}}},
+				},
+			},
+		})
+	}
+
+	if query.Cursor != "" {
+		value, lastID, err := decodeAnimeCursor(query.Cursor, query.Sort)
+		if err != nil {
+			return []entity.Anime{}, 0, "", err
+		}
+
+		dataPipeline = append(dataPipeline, bson.D{
+			{
+				Key: "$match",
+				Value: bson.D{
+					{
+						Key: "$or",
+						Value: bson.A{
+							bson.D{{Key: sortField, Value: bson.D{{Key: comparison, Value: value}}}},
+							bson.D{
+								{Key: sortField, Value: value},
+								{Key: "_id", Value: bson.D{{Key: comparison, Value: lastID}}},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	dataPipeline = append(dataPipeline,
+		bson.D{
+			{
+				Key: "$sort",
+				Value: bson.D{
+					{Key: sortField, Value: direction},
+					{Key: "_id", Value: direction},
+				},
+			},
+		},
+	)
+	if query.Cursor == "" {
+		dataPipeline = append(dataPipeline, bson.D{{Key: "$skip", Value: (query.Page - 1) * query.Limit}})
+	}
+	// one extra document tells whether there is a next page
+	dataPipeline = append(dataPipeline, bson.D{{Key: "$limit", Value: query.Limit + 1}})
+	if query.Sort == "score" {
+		dataPipeline = append(dataPipeline, bson.D{{Key: "$unset", Value: sortField}})
+	}
+	for _, stage := range genreLookup() {
+		dataPipeline = append(dataPipeline, stage)
+	}
+
+	matchDoc := bson.D{
+		{
+			Key:   "$match",
+			Value: filter,
+		},
+	}
+	facetDoc := bson.D{
+		{
+			Key: "$facet",
 			Value: bson.D{
 				{
-					Key:   "created_at",
-					Value: -1,
+					Key:   "total",
+					Value: bson.A{bson.D{{Key: "$count", Value: "total"}}},
+				},
+				{
+					Key:   "data",
+					Value: dataPipeline,
 				},
 			},
 		},
 	}
-	cur, err := anime.DB.Collection("anime").Aggregate(ctx, mongo.Pipeline{sortDoc})
+
+	cur, err := anime.DB.Collection("anime").Aggregate(ctx, mongo.Pipeline{matchDoc, facetDoc})
+	if err != nil {
+		return []entity.Anime{}, 0, "", errors.New(err.Error())
+	}
 	defer cur.Close(ctx)
 
+	facet := struct {
+		Total []struct {
+			Total int64 `bson:"total"`
+		} `bson:"total"`
+		Data []entity.Anime `bson:"data"`
+	}{}
+
+	if cur.Next(ctx) {
+		err := cur.Decode(&facet)
+		if err != nil {
+			return []entity.Anime{}, 0, "", errors.New(err.Error())
+		}
+	}
+
+	var total int64
+	if len(facet.Total) > 0 {
+		total = facet.Total[0].Total
+	}
+
+	result := facet.Data
+	if result == nil {
+		result = []entity.Anime{}
+	}
+
+	nextCursor := ""
+	if len(result) > query.Limit {
+		result = result[:query.Limit]
+		nextCursor = encodeAnimeCursor(result[len(result)-1], query.Sort)
+	}
+
+	return result, total, nextCursor, nil
+}
+
+type animeCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeAnimeCursor(last entity.Anime, sort string) string {
+
+	cursor := animeCursor{ID: last.Id.Hex()}
+	switch sort {
+	case "name":
+		cursor.Value = last.Name
+	case "score":
+		score := 0.0
+		if last.Score != nil {
+			score = *last.Score
+		}
+		cursor.Value = strconv.FormatFloat(score, 'f', -1, 64)
+	default:
+		cursor.Value = last.Created_at.UTC().Format(time.RFC3339Nano)
+	}
+
+	cursorByte, _ := json.Marshal(cursor)
+	return base64.URLEncoding.EncodeToString(cursorByte)
+}
+
+func decodeAnimeCursor(raw string, sort string) (interface{}, primitive.ObjectID, error) {
+
+	cursorByte, err := base64.URLEncoding.DecodeString(raw)
 	if err != nil {
-		return []entity.Anime{}, errors.New(err.Error())
+		return nil, primitive.NilObjectID, ErrCursorInvalid
 	}
 
-	result := []entity.Anime{}
+	cursor := animeCursor{}
+	err = json.Unmarshal(cursorByte, &cursor)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrCursorInvalid
+	}
 
+	lastID, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrCursorInvalid
+	}
+
+	if sort == "name" {
+		return cursor.Value, lastID, nil
+	}
+
+	if sort == "score" {
+		score, err := strconv.ParseFloat(cursor.Value, 64)
+		if err != nil {
+			return nil, primitive.NilObjectID, ErrCursorInvalid
+		}
+		return score, lastID, nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrCursorInvalid
+	}
+
+	return primitive.NewDateTimeFromTime(createdAt), lastID, nil
+}
+
+func (anime *animeRepo) Search(ctx context.Context, userID primitive.ObjectID, query *requestbody.AnimeSearch) ([]entity.AnimeSearch, error) {
+
+	filter := bson.D{
+		{
+			Key:   "user_id",
+			Value: userID,
+		},
+		{
+			Key: "$text",
+			Value: bson.D{
+				{Key: "$search", Value: query.Q},
+			},
+		},
+	}
+	if query.Status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: query.Status})
+	}
+	if query.Genre != "" {
+		genres, err := genreFilter(ctx, anime.DB, query.Genre)
+		if err != nil {
+			return []entity.AnimeSearch{}, err
+		}
+		filter = append(filter, genres)
+	}
+
+	pipeline := mongo.Pipeline{
+		{
+			{
+				Key:   "$match",
+				Value: filter,
+			},
+		},
+		{
+			{
+				Key: "$addFields",
+				Value: bson.D{
+					{Key: "relevance", Value: bson.D{{Key: "$meta", Value: "textScore"}}},
+				},
+			},
+		},
+		{
+			{
+				Key: "$sort",
+				Value: bson.D{
+					{Key: "relevance", Value: -1},
+					{Key: "created_at", Value: -1},
+				},
+			},
+		},
+		{
+			{
+				Key:   "$limit",
+				Value: query.Limit,
+			},
+		},
+	}
+	pipeline = append(pipeline, genreLookup()...)
+
+	cur, err := anime.DB.Collection("anime").Aggregate(ctx, pipeline)
+	if err != nil {
+		return []entity.AnimeSearch{}, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	result := []entity.AnimeSearch{}
 	for cur.Next(ctx) {
-		data := entity.Anime{}
+		data := entity.AnimeSearch{}
 		err := cur.Decode(&data)
 		if err != nil {
-			return []entity.Anime{}, errors.New(err.Error())
+			return []entity.AnimeSearch{}, errors.New(err.Error())
 		}
 
 		result = append(result, data)
@@ -128,6 +721,202 @@ func (anime *animeRepo) GetAll(ctx context.Context) ([]entity.Anime, error) {
 	return result, nil
 }
 
+// GetMany returns the user's entries among ids, keyed by id.
+func (anime *animeRepo) GetMany(ctx context.Context, userID primitive.ObjectID, ids []primitive.ObjectID) (map[primitive.ObjectID]entity.Anime, error) {
+
+	filter := bson.D{
+		{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+		{Key: "user_id", Value: userID},
+	}
+
+	cur, err := anime.DB.Collection("anime").Find(ctx, filter)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	result := map[primitive.ObjectID]entity.Anime{}
+	for cur.Next(ctx) {
+		data := entity.Anime{}
+
+		err := cur.Decode(&data)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		result[data.Id] = data
+	}
+
+	return result, nil
+}
+
+// Bulk runs the writes in one BulkWrite. Without atomic every write stands on
+// its own and failures are reported per item. With atomic the batch runs in a
+// transaction and the first failure rolls everything back with ErrBulkAborted.
+func (anime *animeRepo) Bulk(ctx context.Context, userID primitive.ObjectID, writes []AnimeBulkWrite, atomic bool) ([]entity.AnimeBulkResult, error) {
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	models := []mongo.WriteModel{}
+	results := []entity.AnimeBulkResult{}
+	for _, write := range writes {
+		result := entity.AnimeBulkResult{
+			Index: write.Index,
+			Op:    write.Op,
+		}
+
+		switch write.Op {
+		case "create":
+			genreIDs, err := objectIDs(write.Body.GenreIds)
+			if err != nil {
+				return nil, err
+			}
+
+			id := primitive.NewObjectID()
+			insertDoc := append(bson.D{{Key: "_id", Value: id}}, animeInsertDoc(userID, write.Body, genreIDs, now)...)
+			models = append(models, mongo.NewInsertOneModel().SetDocument(insertDoc))
+			result.Id = id.Hex()
+		case "update":
+			genreIDs, err := objectIDs(write.Body.GenreIds)
+			if err != nil {
+				return nil, err
+			}
+
+			filter := bson.D{
+				{Key: "_id", Value: write.Current.Id},
+				{Key: "user_id", Value: userID},
+			}
+			setDoc := animeSetDoc(write.Body, genreIDs, write.Current, now)
+			models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.D{{Key: "$set", Value: setDoc}}))
+			result.Id = write.Current.Id.Hex()
+		case "delete":
+			filter := bson.D{
+				{Key: "_id", Value: write.Current.Id},
+				{Key: "user_id", Value: userID},
+			}
+			models = append(models, mongo.NewDeleteOneModel().SetFilter(filter))
+			result.Id = write.Current.Id.Hex()
+		}
+
+		results = append(results, result)
+	}
+
+	run := func(ctx context.Context) error {
+
+		for i := range results {
+			results[i].Status = entity.BulkSuccess
+			results[i].Errors = nil
+		}
+
+		_, err := anime.DB.Collection("anime").BulkWrite(ctx, models, options.BulkWrite().SetOrdered(atomic))
+		if bulkErr, ok := err.(mongo.BulkWriteException); ok && bulkErr.WriteConcernError == nil {
+			for _, writeErr := range bulkErr.WriteErrors {
+				results[writeErr.Index].Status = entity.BulkError
+				results[writeErr.Index].Errors = entity.FieldErrors{{Rule: "write", Message: writeErr.Message}}
+			}
+			if atomic {
+				return ErrBulkAborted
+			}
+		} else if err != nil {
+			return errors.New(err.Error())
+		}
+
+		// tags are created with the writes, so an aborted batch leaves none behind
+		tags := []string{}
+		for i, write := range writes {
+			if write.Op != "delete" && results[i].Status == entity.BulkSuccess {
+				tags = append(tags, write.Body.Tags...)
+			}
+		}
+		err = TagRepo(anime.DB).Ensure(ctx, userID, tags)
+		if err != nil {
+			return err
+		}
+
+		// same cascade as Del
+		deleted := []primitive.ObjectID{}
+		for i, write := range writes {
+			if write.Op == "delete" && results[i].Status == entity.BulkSuccess {
+				deleted = append(deleted, write.Current.Id)
+			}
+		}
+		if len(deleted) > 0 {
+			episodeFilter := bson.D{
+				{Key: "anime_id", Value: bson.D{{Key: "$in", Value: deleted}}},
+				{Key: "user_id", Value: userID},
+			}
+			_, err = anime.DB.Collection("anime_episodes").DeleteMany(ctx, episodeFilter)
+			if err != nil {
+				return errors.New(err.Error())
+			}
+		}
+
+		return nil
+	}
+
+	if !atomic {
+		err := run(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return bulkCreatedIds(results), nil
+	}
+
+	session, err := anime.DB.Client().StartSession()
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, run(sessCtx)
+	})
+	if err == ErrBulkAborted {
+		for i := range results {
+			if results[i].Status != entity.BulkError {
+				results[i].Status = entity.BulkSkipped
+			}
+		}
+		return bulkCreatedIds(results), err
+	}
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return results, nil
+}
+
+// bulkCreatedIds drops the pre-generated ids of creates that were not written.
+func bulkCreatedIds(results []entity.AnimeBulkResult) []entity.AnimeBulkResult {
+
+	for i := range results {
+		if results[i].Op == "create" && results[i].Status != entity.BulkSuccess {
+			results[i].Id = ""
+		}
+	}
+
+	return results
+}
+
+// Export returns a cursor over every entry of the user, oldest first, so the
+// caller can stream it instead of holding the whole list in memory.
+// The caller must close the cursor.
+func (anime *animeRepo) Export(ctx context.Context, userID primitive.ObjectID) (*mongo.Cursor, error) {
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "user_id", Value: userID}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}}},
+	}
+	pipeline = append(pipeline, genreLookup()...)
+
+	cur, err := anime.DB.Collection("anime").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return cur, nil
+}
+
 func AnimeRepo(db *mongo.Database) animeRepoInterface {
 	return &animeRepo{
 		DB: db,
@@ -7,13 +7,16 @@ import (
 	"history_anime/src/requestbody"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type authRepoInterface interface {
 	Register(ctx context.Context, body *requestbody.Register) error
 	Login(ctx context.Context, body *requestbody.Login) (*entity.Users, error)
 	ResetPassword(ctx context.Context, email string, newHashPassword string) (*mongo.UpdateResult, error)
+	SetLanguage(ctx context.Context, userID primitive.ObjectID, language string) error
 }
 
 type authRepo struct {
@@ -24,7 +27,7 @@ func (auth *authRepo) Register(ctx context.Context, body *requestbody.Register)
 
 	_, err := auth.DB.Collection("users").InsertOne(ctx, body)
 	if err != nil {
-		return errors.New(err.Error())
+		return writeError(err)
 	}
 
 	return nil
@@ -34,8 +37,11 @@ func (auth *authRepo) Login(ctx context.Context, body *requestbody.Login) (*enti
 
 	filter := bson.D{{Key: "email", Value: body.Email}}
 
+	// same collation as the unique email index, so the lookup is case-insensitive
+	opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
 	result := entity.Users{}
-	err := auth.DB.Collection("users").FindOne(ctx, filter).Decode(&result)
+	err := auth.DB.Collection("users").FindOne(ctx, filter, opts).Decode(&result)
 	if err != nil {
 		return &entity.Users{}, errors.New(err.Error())
 	}
@@ -64,7 +70,10 @@ func (auth *authRepo) ResetPassword(ctx context.Context, email string, newHashPa
 		},
 	}
 
-	up, err := auth.DB.Collection("users").UpdateOne(ctx, filter, updateDoc)
+	// same collation as Login, the token may carry the email in another case
+	opts := options.Update().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+	up, err := auth.DB.Collection("users").UpdateOne(ctx, filter, updateDoc, opts)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -72,6 +81,19 @@ func (auth *authRepo) ResetPassword(ctx context.Context, email string, newHashPa
 	return up, nil
 }
 
+// SetLanguage stores the language the API answers the user in.
+func (auth *authRepo) SetLanguage(ctx context.Context, userID primitive.ObjectID, language string) error {
+
+	updateDoc := bson.D{{Key: "$set", Value: bson.D{{Key: "language", Value: language}}}}
+
+	_, err := auth.DB.Collection("users").UpdateByID(ctx, userID, updateDoc)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
 func AuthRepo(db *mongo.Database) authRepoInterface {
 	return &authRepo{
 		DB: db,
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type episodeRepoInterface interface {
+	Add(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID, body *requestbody.Episode) (string, error)
+	Update(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID, body *requestbody.Episode, id string) (*mongo.UpdateResult, error)
+	Del(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID, id string) (*mongo.DeleteResult, error)
+	GetAll(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID) ([]entity.Episode, error)
+}
+
+type episodeRepo struct {
+	DB *mongo.Database
+}
+
+func (episode *episodeRepo) Add(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID, body *requestbody.Episode) (string, error) {
+
+	now := time.Now()
+	watchedAt := now
+	if body.WatchedAt != nil {
+		watchedAt = *body.WatchedAt
+	}
+
+	insertDoc := bson.D{
+		{Key: "anime_id", Value: animeID},
+		{Key: "user_id", Value: userID},
+		{Key: "episode", Value: body.Episode},
+		{Key: "note", Value: body.Note},
+		{Key: "watched_at", Value: primitive.NewDateTimeFromTime(watchedAt)},
+		{Key: "created_at", Value: primitive.NewDateTimeFromTime(now)},
+	}
+	insert, err := episode.DB.Collection("anime_episodes").InsertOne(ctx, insertDoc)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	insertID, ok := insert.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", errors.New("type error")
+	}
+
+	return insertID.Hex(), nil
+}
+
+func (episode *episodeRepo) Update(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID, body *requestbody.Episode, id string) (*mongo.UpdateResult, error) {
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	setDoc := bson.D{
+		{Key: "episode", Value: body.Episode},
+		{Key: "note", Value: body.Note},
+	}
+	if body.WatchedAt != nil {
+		setDoc = append(setDoc, bson.E{Key: "watched_at", Value: primitive.NewDateTimeFromTime(*body.WatchedAt)})
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objId},
+		{Key: "anime_id", Value: animeID},
+		{Key: "user_id", Value: userID},
+	}
+
+	up, err := episode.DB.Collection("anime_episodes").UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: setDoc}})
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return up, nil
+}
+
+func (episode *episodeRepo) Del(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID, id string) (*mongo.DeleteResult, error) {
+
+	objId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objId},
+		{Key: "anime_id", Value: animeID},
+		{Key: "user_id", Value: userID},
+	}
+
+	result, err := episode.DB.Collection("anime_episodes").DeleteOne(ctx, filter)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return result, nil
+}
+
+func (episode *episodeRepo) GetAll(ctx context.Context, userID primitive.ObjectID, animeID primitive.ObjectID) ([]entity.Episode, error) {
+
+	pipeline := mongo.Pipeline{
+		{
+			{
+				Key: "$match",
+				Value: bson.D{
+					{Key: "anime_id", Value: animeID},
+					{Key: "user_id", Value: userID},
+				},
+			},
+		},
+		{
+			{
+				Key: "$sort",
+				Value: bson.D{
+					{Key: "watched_at", Value: -1},
+				},
+			},
+		},
+	}
+
+	cur, err := episode.DB.Collection("anime_episodes").Aggregate(ctx, pipeline)
+	if err != nil {
+		return []entity.Episode{}, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	result := []entity.Episode{}
+	for cur.Next(ctx) {
+		data := entity.Episode{}
+		err := cur.Decode(&data)
+		if err != nil {
+			return []entity.Episode{}, errors.New(err.Error())
+		}
+
+		result = append(result, data)
+	}
+
+	return result, nil
+}
+
+func EpisodeRepo(db *mongo.Database) episodeRepoInterface {
+	return &episodeRepo{
+		DB: db,
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrInvalidID = errors.New("id invalid")
+var ErrCursorInvalid = errors.New("cursor invalid")
+var ErrGenreTargetNotFound = errors.New("target genre not found")
+var ErrGenreParentNotFound = errors.New("parent genre not found")
+var ErrGenreCycle = errors.New("genre cannot be moved under itself or one of its descendants")
+var ErrBulkAborted = errors.New("bulk write aborted, no changes were applied")
+
+// GenreInUseError is returned when a genre cannot be deleted because anime
+// still reference it.
+type GenreInUseError struct {
+	Count int64
+}
+
+func (err *GenreInUseError) Error() string {
+	return fmt.Sprintf("genre is used by %d anime", err.Count)
+}
+
+// DuplicateKeyError is returned when a write hits one of the unique indexes.
+// Field is the indexed field, unique indexes are named "<field>_unique".
+type DuplicateKeyError struct {
+	Field string
+}
+
+func (err *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%s already exists", err.Field)
+}
+
+var duplicateIndexPattern = regexp.MustCompile(`index: (\S+) dup key`)
+
+// writeError translates duplicate-key errors into *DuplicateKeyError and wraps
+// every other error the way the repositories always have.
+func writeError(err error) error {
+
+	if !mongo.IsDuplicateKeyError(err) {
+		return errors.New(err.Error())
+	}
+
+	field := ""
+	match := duplicateIndexPattern.FindStringSubmatch(err.Error())
+	if match != nil {
+		field = strings.TrimSuffix(match[1], "_unique")
+	}
+
+	return &DuplicateKeyError{Field: field}
+}
@@ -5,17 +5,23 @@ import (
 	"errors"
 	"history_anime/src/entity"
 	"history_anime/src/requestbody"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type genreInterface interface {
 	GetAll(ctx context.Context) ([]entity.Genre, error)
-	Add(ctx context.Context, body *requestbody.Genre) (string, error)
-	Del(ctx context.Context, id string) (*mongo.DeleteResult, error)
+	Add(ctx context.Context, userID primitive.ObjectID, body *requestbody.Genre) (string, error)
+	Update(ctx context.Context, userID primitive.ObjectID, id string, body *requestbody.Genre) (*mongo.UpdateResult, error)
+	Del(ctx context.Context, id string, body *requestbody.GenreDelete) (*mongo.DeleteResult, int64, error)
+	Missing(ctx context.Context, ids []string) ([]string, error)
+	Stats(ctx context.Context, userID primitive.ObjectID) ([]entity.GenreStats, error)
+	Resolve(ctx context.Context, userID primitive.ObjectID, names []string) ([]primitive.ObjectID, error)
 }
 
 type genreRepo struct {
@@ -58,21 +64,113 @@ func (genre *genreRepo) GetAll(ctx context.Context) ([]entity.Genre, error) {
 
 }
 
-func (genre *genreRepo) Add(ctx context.Context, body *requestbody.Genre) (string, error) {
+func (genre *genreRepo) Stats(ctx context.Context, userID primitive.ObjectID) ([]entity.GenreStats, error) {
+
+	// group per genre and status first, then fold the statuses into one document per genre
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "user_id", Value: userID}}}},
+		{{Key: "$unwind", Value: "$genre_ids"}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "genre", Value: "$genre_ids"},
+				{Key: "status", Value: "$status"},
+			}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "score_sum", Value: bson.D{{Key: "$sum", Value: "$score"}}},
+			{Key: "score_count", Value: bson.D{{Key: "$sum", Value: bson.D{
+				{Key: "$cond", Value: bson.A{bson.D{{Key: "$isNumber", Value: "$score"}}, 1, 0}},
+			}}}},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$_id.genre"},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: "$count"}}},
+			{Key: "status", Value: bson.D{{Key: "$push", Value: bson.D{
+				// $arrayToObject fails on a null key, legacy entries may have no status
+				{Key: "k", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$_id.status", "unknown"}}}},
+				{Key: "v", Value: "$count"},
+			}}}},
+			{Key: "score_sum", Value: bson.D{{Key: "$sum", Value: "$score_sum"}}},
+			{Key: "score_count", Value: bson.D{{Key: "$sum", Value: "$score_count"}}},
+		}}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: "genre"},
+			{Key: "localField", Value: "_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "genre"},
+		}}},
+		{{Key: "$unwind", Value: "$genre"}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "name", Value: "$genre.name"},
+			{Key: "total", Value: 1},
+			{Key: "status", Value: bson.D{{Key: "$arrayToObject", Value: "$status"}}},
+			{Key: "average_score", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gt", Value: bson.A{"$score_count", 0}}},
+				bson.D{{Key: "$divide", Value: bson.A{"$score_sum", "$score_count"}}},
+				nil,
+			}}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{
+			{Key: "total", Value: -1},
+			{Key: "name", Value: 1},
+		}}},
+	}
+
+	cur, err := genre.DB.Collection("anime").Aggregate(ctx, pipeline)
+	if err != nil {
+		return []entity.GenreStats{}, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	stats := []entity.GenreStats{}
+	for cur.Next(ctx) {
+		data := entity.GenreStats{}
+
+		err := cur.Decode(&data)
+		if err != nil {
+			return []entity.GenreStats{}, errors.New(err.Error())
+		}
+
+		// every status is listed so clients don't have to guess missing keys
+		for _, status := range entity.AnimeStatuses {
+			if _, ok := data.Status[string(status)]; !ok {
+				data.Status[string(status)] = 0
+			}
+		}
+
+		stats = append(stats, data)
+	}
+
+	return stats, nil
+}
+
+func (genre *genreRepo) Add(ctx context.Context, userID primitive.ObjectID, body *requestbody.Genre) (string, error) {
+
+	parentID, err := genre.parent(ctx, body.ParentId)
+	if err != nil {
+		return "", err
+	}
 
 	insertDoc := bson.D{
 		{
 			Key:   "name",
-			Value: body.Name,
+			Value: strings.TrimSpace(body.Name),
+		},
+		{
+			Key:   "created_by",
+			Value: userID,
 		},
 		{
 			Key:   "created_at",
 			Value: primitive.NewDateTimeFromTime(time.Now()),
 		},
 	}
+	if parentID != nil {
+		insertDoc = append(insertDoc, bson.E{Key: "parent_id", Value: *parentID})
+	}
+
 	insert, err := genre.DB.Collection("genre").InsertOne(ctx, insertDoc)
 	if err != nil {
-		return "", errors.New(err.Error())
+		return "", writeError(err)
 	}
 
 	insertedID, ok := insert.InsertedID.(primitive.ObjectID)
@@ -83,28 +181,329 @@ func (genre *genreRepo) Add(ctx context.Context, body *requestbody.Genre) (strin
 	return insertedID.Hex(), nil
 }
 
-func (genre *genreRepo) Del(ctx context.Context, id string) (*mongo.DeleteResult, error) {
+// Update records userID as updated_by, genres are shared between users.
+func (genre *genreRepo) Update(ctx context.Context, userID primitive.ObjectID, id string, body *requestbody.Genre) (*mongo.UpdateResult, error) {
 
 	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	parentID, err := genre.parent(ctx, body.ParentId)
+	if err != nil {
+		return nil, err
+	}
+
+	setDoc := bson.D{
+		{Key: "name", Value: strings.TrimSpace(body.Name)},
+		{Key: "updated_by", Value: userID},
+		{Key: "updated_at", Value: primitive.NewDateTimeFromTime(time.Now())},
+	}
+	updateDoc := bson.D{}
+
+	if parentID != nil {
+		descendants, err := genreDescendants(ctx, genre.DB, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		if *parentID == objID {
+			return nil, ErrGenreCycle
+		}
+		for _, descendant := range descendants {
+			if descendant == *parentID {
+				return nil, ErrGenreCycle
+			}
+		}
+
+		setDoc = append(setDoc, bson.E{Key: "parent_id", Value: *parentID})
+	} else {
+		updateDoc = append(updateDoc, bson.E{Key: "$unset", Value: bson.D{{Key: "parent_id", Value: ""}}})
+	}
+	updateDoc = append(updateDoc, bson.E{Key: "$set", Value: setDoc})
+
+	up, err := genre.DB.Collection("genre").UpdateOne(ctx, bson.D{{Key: "_id", Value: objID}}, updateDoc)
+	if err != nil {
+		return nil, writeError(err)
+	}
+
+	return up, nil
+}
+
+// Resolve looks genres up by name, ignoring case, and creates the ones that
+// don't exist yet.
+func (genre *genreRepo) Resolve(ctx context.Context, userID primitive.ObjectID, names []string) ([]primitive.ObjectID, error) {
+
+	opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+	ids := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		data := entity.Genre{}
+		err := genre.DB.Collection("genre").FindOne(ctx, bson.D{{Key: "name", Value: name}}, opts).Decode(&data)
+		if err == mongo.ErrNoDocuments {
+			insertedID, err := genre.Add(ctx, userID, &requestbody.Genre{Name: name})
+			if _, ok := err.(*DuplicateKeyError); ok {
+				// created by someone else in the meantime
+				err = genre.DB.Collection("genre").FindOne(ctx, bson.D{{Key: "name", Value: name}}, opts).Decode(&data)
+				if err != nil {
+					return nil, errors.New(err.Error())
+				}
+			} else if err != nil {
+				return nil, err
+			} else {
+				data.Id, _ = primitive.ObjectIDFromHex(insertedID)
+			}
+		} else if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		if !seen[data.Id] {
+			seen[data.Id] = true
+			ids = append(ids, data.Id)
+		}
+	}
+
+	return ids, nil
+}
+
+// parent resolves an optional parent id and checks the parent exists.
+func (genre *genreRepo) parent(ctx context.Context, id string) (*primitive.ObjectID, error) {
+
+	if id == "" {
+		return nil, nil
+	}
+
+	parentID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	count, err := genre.DB.Collection("genre").CountDocuments(ctx, bson.D{{Key: "_id", Value: parentID}})
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
+	if count == 0 {
+		return nil, ErrGenreParentNotFound
+	}
 
-	filter := bson.D{
+	return &parentID, nil
+}
+
+// genreDescendants returns every genre below id, at any depth.
+func genreDescendants(ctx context.Context, db *mongo.Database, id primitive.ObjectID) ([]primitive.ObjectID, error) {
+
+	pipeline := mongo.Pipeline{
 		{
-			Key:   "_id",
-			Value: objID,
+			{
+				Key:   "$match",
+				Value: bson.D{{Key: "_id", Value: id}},
+			},
+		},
+		{
+			{
+				Key: "$graphLookup",
+				Value: bson.D{
+					{Key: "from", Value: "genre"},
+					{Key: "startWith", Value: "$_id"},
+					{Key: "connectFromField", Value: "_id"},
+					{Key: "connectToField", Value: "parent_id"},
+					{Key: "as", Value: "descendants"},
+				},
+			},
 		},
 	}
-	result, err := genre.DB.Collection("genre").DeleteOne(ctx, filter)
 
+	cur, err := db.Collection("genre").Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
+	defer cur.Close(ctx)
+
+	result := []primitive.ObjectID{}
+	if cur.Next(ctx) {
+		data := struct {
+			Descendants []entity.Genre `bson:"descendants"`
+		}{}
+
+		err := cur.Decode(&data)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		for _, descendant := range data.Descendants {
+			result = append(result, descendant.Id)
+		}
+	}
 
 	return result, nil
 }
 
+// genreFilter matches anime tagged with the genre or any of its sub-genres.
+func genreFilter(ctx context.Context, db *mongo.Database, id string) (bson.E, error) {
+
+	genreID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return bson.E{}, ErrInvalidID
+	}
+
+	descendants, err := genreDescendants(ctx, db, genreID)
+	if err != nil {
+		return bson.E{}, err
+	}
+
+	ids := append([]primitive.ObjectID{genreID}, descendants...)
+	return bson.E{Key: "genre_ids", Value: bson.D{{Key: "$in", Value: ids}}}, nil
+}
+
+// Del removes a genre inside a transaction. By default it refuses with a
+// *GenreInUseError while anime reference the genre; the detach strategy pulls
+// it from those anime and reassign moves them to body.To. The returned count
+// is the number of anime that were changed.
+func (genre *genreRepo) Del(ctx context.Context, id string, body *requestbody.GenreDelete) (*mongo.DeleteResult, int64, error) {
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, 0, ErrInvalidID
+	}
+
+	var targetID primitive.ObjectID
+	if body.Strategy == "reassign" {
+		targetID, err = primitive.ObjectIDFromHex(body.To)
+		if err != nil {
+			return nil, 0, ErrInvalidID
+		}
+	}
+
+	session, err := genre.DB.Client().StartSession()
+	if err != nil {
+		return nil, 0, errors.New(err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	var modified int64
+	result, err := session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+
+		modified = 0
+		current := entity.Genre{}
+		err := genre.DB.Collection("genre").FindOne(sessCtx, bson.D{{Key: "_id", Value: objID}}).Decode(&current)
+		if err == mongo.ErrNoDocuments {
+			return &mongo.DeleteResult{DeletedCount: 0}, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		used := bson.D{{Key: "genre_ids", Value: objID}}
+		switch body.Strategy {
+		case "detach":
+			up, err := genre.DB.Collection("anime").UpdateMany(sessCtx, used,
+				bson.D{{Key: "$pull", Value: bson.D{{Key: "genre_ids", Value: objID}}}},
+			)
+			if err != nil {
+				return nil, err
+			}
+			modified = up.ModifiedCount
+
+		case "reassign":
+			target, err := genre.DB.Collection("genre").CountDocuments(sessCtx, bson.D{{Key: "_id", Value: targetID}})
+			if err != nil {
+				return nil, err
+			}
+			if target == 0 {
+				return nil, ErrGenreTargetNotFound
+			}
+
+			// $addToSet and $pull cannot touch the same field in one update
+			_, err = genre.DB.Collection("anime").UpdateMany(sessCtx, used,
+				bson.D{{Key: "$addToSet", Value: bson.D{{Key: "genre_ids", Value: targetID}}}},
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			up, err := genre.DB.Collection("anime").UpdateMany(sessCtx, used,
+				bson.D{{Key: "$pull", Value: bson.D{{Key: "genre_ids", Value: objID}}}},
+			)
+			if err != nil {
+				return nil, err
+			}
+			modified = up.ModifiedCount
+
+		default:
+			count, err := genre.DB.Collection("anime").CountDocuments(sessCtx, used)
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				return nil, &GenreInUseError{Count: count}
+			}
+		}
+
+		// sub-genres move up to the deleted genre's parent
+		reparent := bson.D{{Key: "$unset", Value: bson.D{{Key: "parent_id", Value: ""}}}}
+		if current.ParentId != nil {
+			reparent = bson.D{{Key: "$set", Value: bson.D{{Key: "parent_id", Value: *current.ParentId}}}}
+		}
+		_, err = genre.DB.Collection("genre").UpdateMany(sessCtx, bson.D{{Key: "parent_id", Value: objID}}, reparent)
+		if err != nil {
+			return nil, err
+		}
+
+		return genre.DB.Collection("genre").DeleteOne(sessCtx, bson.D{{Key: "_id", Value: objID}})
+	})
+
+	var inUse *GenreInUseError
+	if errors.As(err, &inUse) || err == ErrGenreTargetNotFound {
+		return nil, 0, err
+	}
+	if err != nil {
+		return nil, 0, errors.New(err.Error())
+	}
+
+	return result.(*mongo.DeleteResult), modified, nil
+}
+
+// Missing returns the ids that have no document in the genre collection.
+func (genre *genreRepo) Missing(ctx context.Context, ids []string) ([]string, error) {
+
+	objIDs, err := objectIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{
+			Key:   "_id",
+			Value: bson.D{{Key: "$in", Value: objIDs}},
+		},
+	}
+	found, err := genre.DB.Collection("genre").Distinct(ctx, "_id", filter)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	exists := map[primitive.ObjectID]bool{}
+	for _, id := range found {
+		if objID, ok := id.(primitive.ObjectID); ok {
+			exists[objID] = true
+		}
+	}
+
+	missing := []string{}
+	for _, objID := range objIDs {
+		if !exists[objID] {
+			missing = append(missing, objID.Hex())
+		}
+	}
+
+	return missing, nil
+}
+
 func GenreRepo(db *mongo.Database) genreInterface {
 	return &genreRepo{
 		DB: db,
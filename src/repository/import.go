@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+	"history_anime/src/validation"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// malStatusCodes covers exports that store my_status as a number.
+var malStatusCodes = map[string]entity.AnimeStatus{
+	"1": entity.StatusWatching,
+	"2": entity.StatusCompleted,
+	"3": entity.StatusOnHold,
+	"4": entity.StatusDropped,
+	"6": entity.StatusPlanToWatch,
+}
+
+type importRepoInterface interface {
+	Mal(ctx context.Context, userID primitive.ObjectID, body *requestbody.MalExport, genreIDs []primitive.ObjectID) ([]entity.ImportRow, error)
+}
+
+type importRepo struct {
+	DB *mongo.Database
+}
+
+// Mal creates entries missing from the user's list and brings existing ones,
+// matched by title ignoring case, in line with the export. Status transitions
+// are not enforced, MyAnimeList is taken as the source of truth.
+func (imp *importRepo) Mal(ctx context.Context, userID primitive.ObjectID, body *requestbody.MalExport, genreIDs []primitive.ObjectID) ([]entity.ImportRow, error) {
+
+	opts := options.FindOne().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+	rows := []entity.ImportRow{}
+	seen := map[string]bool{}
+	allTags := []string{}
+	for i, item := range body.Anime {
+		title := strings.TrimSpace(item.Title)
+		row := entity.ImportRow{
+			Row:    i + 1,
+			Title:  title,
+			Result: entity.ImportSkipped,
+		}
+
+		if title == "" {
+			row.Reason = "missing title"
+			rows = append(rows, row)
+			continue
+		}
+
+		key := strings.ToLower(title)
+		if seen[key] {
+			row.Reason = "duplicate in file"
+			rows = append(rows, row)
+			continue
+		}
+		seen[key] = true
+
+		status, ok := malStatus(item.Status)
+		if !ok {
+			row.Reason = "unknown status '%s'"
+			row.ReasonArgs = []interface{}{item.Status}
+			rows = append(rows, row)
+			continue
+		}
+
+		var score *float64
+		if item.Score >= 1 && item.Score <= 10 {
+			score = &item.Score
+		}
+
+		watched := item.WatchedEpisodes
+		if item.Episodes > 0 && watched > item.Episodes {
+			watched = item.Episodes
+		}
+
+		tags := normalizeTags(strings.Split(item.Tags, ","))
+		errResult := validation.ValidateTags(tags)
+		if len(errResult) > 0 {
+			row.Reason = "invalid tags"
+			row.Errors = errResult
+			rows = append(rows, row)
+			continue
+		}
+
+		current := entity.Anime{}
+		filter := bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "name", Value: title},
+		}
+		err := imp.DB.Collection("anime").FindOne(ctx, filter, opts).Decode(&current)
+		if err == mongo.ErrNoDocuments {
+			now := primitive.NewDateTimeFromTime(time.Now())
+			insertDoc := bson.D{
+				{Key: "user_id", Value: userID},
+				{Key: "name", Value: title},
+				{Key: "image", Value: ""},
+				{Key: "genre_ids", Value: genreIDs},
+				{Key: "tags", Value: tags},
+				{Key: "description", Value: ""},
+				{Key: "status", Value: status},
+				{Key: "total_episodes", Value: item.Episodes},
+				{Key: "watched_episodes", Value: watched},
+				{Key: "created_at", Value: now},
+				{Key: "updated_at", Value: now},
+			}
+			if score != nil {
+				insertDoc = append(insertDoc, bson.E{Key: "score", Value: *score})
+			}
+			insertDoc = append(insertDoc, statusTimestamps(nil, status, now)...)
+
+			insert, err := imp.DB.Collection("anime").InsertOne(ctx, insertDoc)
+			if err != nil {
+				return nil, errors.New(err.Error())
+			}
+
+			insertID, ok := insert.InsertedID.(primitive.ObjectID)
+			if !ok {
+				return nil, errors.New("inserted id is not an ObjectID")
+			}
+
+			row.Result = entity.ImportCreated
+			row.Id = insertID.Hex()
+			rows = append(rows, row)
+			allTags = append(allTags, tags...)
+			continue
+		}
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		row.Id = current.Id.Hex()
+
+		// without an episode count in the file the stored one still bounds watched
+		if item.Episodes == 0 && current.TotalEpisodes > 0 && watched > current.TotalEpisodes {
+			watched = current.TotalEpisodes
+		}
+
+		now := primitive.NewDateTimeFromTime(time.Now())
+		setDoc := bson.D{}
+		if current.Status != status {
+			setDoc = append(setDoc, bson.E{Key: "status", Value: status})
+			setDoc = append(setDoc, statusTimestamps(&current, status, now)...)
+		}
+		if item.Episodes > 0 && current.TotalEpisodes != item.Episodes {
+			setDoc = append(setDoc, bson.E{Key: "total_episodes", Value: item.Episodes})
+		}
+		if current.WatchedEpisodes != watched {
+			setDoc = append(setDoc, bson.E{Key: "watched_episodes", Value: watched})
+		}
+		if score != nil && (current.Score == nil || *current.Score != *score) {
+			setDoc = append(setDoc, bson.E{Key: "score", Value: *score})
+		}
+
+		newTags := []string{}
+		for _, name := range tags {
+			found := false
+			for _, existing := range current.Tags {
+				if existing == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				newTags = append(newTags, name)
+			}
+		}
+
+		// the new tags are added to the stored ones, the count limit applies to both
+		errResult = validation.ValidateTags(append(append([]string{}, current.Tags...), newTags...))
+		if len(errResult) > 0 {
+			row.Reason = "invalid tags"
+			row.Errors = errResult
+			rows = append(rows, row)
+			continue
+		}
+
+		if len(setDoc) == 0 && len(newTags) == 0 {
+			row.Reason = "already up to date"
+			rows = append(rows, row)
+			continue
+		}
+
+		setDoc = append(setDoc, bson.E{Key: "updated_at", Value: now})
+		updateDoc := bson.D{{Key: "$set", Value: setDoc}}
+		if len(newTags) > 0 {
+			updateDoc = append(updateDoc, bson.E{Key: "$addToSet", Value: bson.D{
+				{Key: "tags", Value: bson.D{{Key: "$each", Value: newTags}}},
+			}})
+		}
+
+		_, err = imp.DB.Collection("anime").UpdateByID(ctx, current.Id, updateDoc)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		row.Result = entity.ImportUpdated
+		rows = append(rows, row)
+		allTags = append(allTags, newTags...)
+	}
+
+	err := TagRepo(imp.DB).Ensure(ctx, userID, allTags)
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func malStatus(raw string) (entity.AnimeStatus, bool) {
+
+	if status, ok := malStatusCodes[strings.TrimSpace(raw)]; ok {
+		return status, true
+	}
+
+	return entity.ParseAnimeStatus(raw)
+}
+
+func ImportRepo(db *mongo.Database) importRepoInterface {
+	return &importRepo{
+		DB: db,
+	}
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const statsTopGenres = 5
+
+type statsRepoInterface interface {
+	Get(ctx context.Context, userID primitive.ObjectID, query *requestbody.StatsQuery) (*entity.Stats, error)
+}
+
+type statsRepo struct {
+	DB *mongo.Database
+}
+
+func (stats *statsRepo) Get(ctx context.Context, userID primitive.ObjectID, query *requestbody.StatsQuery) (*entity.Stats, error) {
+
+	dateRange, err := statsDateRange(query)
+	if err != nil {
+		return nil, err
+	}
+
+	animeMatch := bson.D{{Key: "user_id", Value: userID}}
+	if len(dateRange) > 0 {
+		animeMatch = append(animeMatch, bson.E{Key: "created_at", Value: dateRange})
+	}
+
+	pipeline := mongo.Pipeline{
+		{
+			{Key: "$match", Value: animeMatch},
+		},
+		{
+			{
+				Key: "$facet",
+				Value: bson.D{
+					{
+						Key: "status",
+						Value: bson.A{
+							bson.D{{Key: "$group", Value: bson.D{
+								{Key: "_id", Value: "$status"},
+								{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+							}}},
+						},
+					},
+					{
+						Key: "added_per_month",
+						Value: bson.A{
+							bson.D{{Key: "$group", Value: bson.D{
+								{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
+									{Key: "format", Value: "%Y-%m"},
+									{Key: "date", Value: "$created_at"},
+								}}}},
+								{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+							}}},
+							bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+						},
+					},
+					{
+						Key: "top_genres",
+						Value: bson.A{
+							bson.D{{Key: "$unwind", Value: "$genre_ids"}},
+							bson.D{{Key: "$group", Value: bson.D{
+								{Key: "_id", Value: "$genre_ids"},
+								{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+							}}},
+							bson.D{{Key: "$sort", Value: bson.D{
+								{Key: "count", Value: -1},
+								{Key: "_id", Value: 1},
+							}}},
+							bson.D{{Key: "$limit", Value: statsTopGenres}},
+							bson.D{{Key: "$lookup", Value: bson.D{
+								{Key: "from", Value: "genre"},
+								{Key: "localField", Value: "_id"},
+								{Key: "foreignField", Value: "_id"},
+								{Key: "as", Value: "genre"},
+							}}},
+							bson.D{{Key: "$unwind", Value: "$genre"}},
+							bson.D{{Key: "$project", Value: bson.D{
+								{Key: "name", Value: "$genre.name"},
+								{Key: "count", Value: 1},
+							}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cur, err := stats.DB.Collection("anime").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	facet := struct {
+		Status []struct {
+			Status string `bson:"_id"`
+			Count  int64  `bson:"count"`
+		} `bson:"status"`
+		AddedPerMonth []entity.StatsPeriod `bson:"added_per_month"`
+		TopGenres     []entity.StatsGenre  `bson:"top_genres"`
+	}{}
+	if cur.Next(ctx) {
+		err := cur.Decode(&facet)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+	}
+
+	result := &entity.Stats{
+		Status:          map[string]int64{},
+		AddedPerMonth:   []entity.StatsPeriod{},
+		EpisodesPerWeek: []entity.StatsPeriod{},
+		TopGenres:       []entity.StatsGenre{},
+	}
+	for _, status := range entity.AnimeStatuses {
+		result.Status[string(status)] = 0
+	}
+	for _, status := range facet.Status {
+		result.Status[status.Status] = status.Count
+		result.Total += status.Count
+	}
+	if facet.AddedPerMonth != nil {
+		result.AddedPerMonth = facet.AddedPerMonth
+	}
+	if facet.TopGenres != nil {
+		result.TopGenres = facet.TopGenres
+	}
+
+	// completion is measured against entries the user actually started
+	started := result.Total - result.Status[string(entity.StatusPlanToWatch)]
+	if started > 0 {
+		result.CompletionRate = float64(result.Status[string(entity.StatusCompleted)]) / float64(started)
+	}
+
+	episodes, err := stats.episodesPerWeek(ctx, userID, dateRange)
+	if err != nil {
+		return nil, err
+	}
+	result.EpisodesPerWeek = episodes
+
+	return result, nil
+}
+
+func (stats *statsRepo) episodesPerWeek(ctx context.Context, userID primitive.ObjectID, dateRange bson.D) ([]entity.StatsPeriod, error) {
+
+	match := bson.D{{Key: "user_id", Value: userID}}
+	if len(dateRange) > 0 {
+		match = append(match, bson.E{Key: "watched_at", Value: dateRange})
+	}
+
+	pipeline := mongo.Pipeline{
+		{
+			{Key: "$match", Value: match},
+		},
+		{
+			{
+				Key: "$group",
+				Value: bson.D{
+					{Key: "_id", Value: bson.D{{Key: "$dateToString", Value: bson.D{
+						{Key: "format", Value: "%G-W%V"},
+						{Key: "date", Value: "$watched_at"},
+					}}}},
+					{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+				},
+			},
+		},
+		{
+			{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}},
+		},
+	}
+
+	cur, err := stats.DB.Collection("anime_episodes").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	weeks := []entity.StatsPeriod{}
+	for cur.Next(ctx) {
+		data := entity.StatsPeriod{}
+
+		err := cur.Decode(&data)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+
+		weeks = append(weeks, data)
+	}
+
+	return weeks, nil
+}
+
+// statsDateRange turns the inclusive from/to days into a $gte/$lt filter.
+func statsDateRange(query *requestbody.StatsQuery) (bson.D, error) {
+
+	dateRange := bson.D{}
+	if query.From != "" {
+		from, err := time.Parse(time.DateOnly, query.From)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+		dateRange = append(dateRange, bson.E{Key: "$gte", Value: primitive.NewDateTimeFromTime(from)})
+	}
+
+	if query.To != "" {
+		to, err := time.Parse(time.DateOnly, query.To)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+		dateRange = append(dateRange, bson.E{Key: "$lt", Value: primitive.NewDateTimeFromTime(to.AddDate(0, 0, 1))})
+	}
+
+	return dateRange, nil
+}
+
+func StatsRepo(db *mongo.Database) statsRepoInterface {
+	return &statsRepo{
+		DB: db,
+	}
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type tagRepoInterface interface {
+	GetAll(ctx context.Context, userID primitive.ObjectID) ([]entity.Tag, error)
+	Add(ctx context.Context, userID primitive.ObjectID, body *requestbody.Tag) (string, error)
+	Update(ctx context.Context, userID primitive.ObjectID, body *requestbody.Tag, id string) (*mongo.UpdateResult, error)
+	Del(ctx context.Context, userID primitive.ObjectID, id string) (*mongo.DeleteResult, error)
+	Ensure(ctx context.Context, userID primitive.ObjectID, names []string) error
+}
+
+type tagRepo struct {
+	DB *mongo.Database
+}
+
+// normalizeTags lowercases and trims tag names and drops empty and repeated ones.
+func normalizeTags(names []string) []string {
+
+	seen := map[string]bool{}
+	result := []string{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+
+	return result
+}
+
+func (tag *tagRepo) GetAll(ctx context.Context, userID primitive.ObjectID) ([]entity.Tag, error) {
+
+	pipeline := mongo.Pipeline{
+		{
+			{
+				Key:   "$match",
+				Value: bson.D{{Key: "user_id", Value: userID}},
+			},
+		},
+		{
+			{
+				Key:   "$sort",
+				Value: bson.D{{Key: "name", Value: 1}},
+			},
+		},
+	}
+
+	cur, err := tag.DB.Collection("tag").Aggregate(ctx, pipeline)
+	if err != nil {
+		return []entity.Tag{}, errors.New(err.Error())
+	}
+	defer cur.Close(ctx)
+
+	tags := []entity.Tag{}
+	for cur.Next(ctx) {
+		data := entity.Tag{}
+
+		err := cur.Decode(&data)
+		if err != nil {
+			return []entity.Tag{}, errors.New(err.Error())
+		}
+
+		tags = append(tags, data)
+	}
+
+	return tags, nil
+}
+
+func (tag *tagRepo) Add(ctx context.Context, userID primitive.ObjectID, body *requestbody.Tag) (string, error) {
+
+	insertDoc := bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "name", Value: strings.ToLower(strings.TrimSpace(body.Name))},
+		{Key: "created_at", Value: primitive.NewDateTimeFromTime(time.Now())},
+	}
+
+	insert, err := tag.DB.Collection("tag").InsertOne(ctx, insertDoc)
+	if err != nil {
+		return "", writeError(err)
+	}
+
+	insertedID, ok := insert.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", errors.New("type error")
+	}
+
+	return insertedID.Hex(), nil
+}
+
+// Update renames a tag and the copies of its name stored on the user's anime.
+func (tag *tagRepo) Update(ctx context.Context, userID primitive.ObjectID, body *requestbody.Tag, id string) (*mongo.UpdateResult, error) {
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	name := strings.ToLower(strings.TrimSpace(body.Name))
+	filter := bson.D{
+		{Key: "_id", Value: objID},
+		{Key: "user_id", Value: userID},
+	}
+
+	session, err := tag.DB.Client().StartSession()
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+
+		current := entity.Tag{}
+		err := tag.DB.Collection("tag").FindOne(sessCtx, filter).Decode(&current)
+		if err == mongo.ErrNoDocuments {
+			return &mongo.UpdateResult{MatchedCount: 0}, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		up, err := tag.DB.Collection("tag").UpdateOne(sessCtx, filter, bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: name}}}})
+		if err != nil {
+			return nil, err
+		}
+
+		used := bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "tags", Value: current.Name},
+		}
+		_, err = tag.DB.Collection("anime").UpdateMany(sessCtx, used,
+			bson.D{{Key: "$addToSet", Value: bson.D{{Key: "tags", Value: name}}}},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if current.Name != name {
+			_, err = tag.DB.Collection("anime").UpdateMany(sessCtx, used,
+				bson.D{{Key: "$pull", Value: bson.D{{Key: "tags", Value: current.Name}}}},
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return up, nil
+	})
+	if err != nil {
+		return nil, writeError(err)
+	}
+
+	return result.(*mongo.UpdateResult), nil
+}
+
+// Del removes a tag and pulls it from the user's anime.
+func (tag *tagRepo) Del(ctx context.Context, userID primitive.ObjectID, id string) (*mongo.DeleteResult, error) {
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	filter := bson.D{
+		{Key: "_id", Value: objID},
+		{Key: "user_id", Value: userID},
+	}
+
+	session, err := tag.DB.Client().StartSession()
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+
+		current := entity.Tag{}
+		err := tag.DB.Collection("tag").FindOneAndDelete(sessCtx, filter).Decode(&current)
+		if err == mongo.ErrNoDocuments {
+			return &mongo.DeleteResult{DeletedCount: 0}, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		_, err = tag.DB.Collection("anime").UpdateMany(sessCtx,
+			bson.D{
+				{Key: "user_id", Value: userID},
+				{Key: "tags", Value: current.Name},
+			},
+			bson.D{{Key: "$pull", Value: bson.D{{Key: "tags", Value: current.Name}}}},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mongo.DeleteResult{DeletedCount: 1}, nil
+	})
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return result.(*mongo.DeleteResult), nil
+}
+
+// Ensure creates the tags a user attaches to an anime that do not exist yet.
+func (tag *tagRepo) Ensure(ctx context.Context, userID primitive.ObjectID, names []string) error {
+
+	names = normalizeTags(names)
+	if len(names) == 0 {
+		return nil
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	models := []mongo.WriteModel{}
+	for _, name := range names {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{
+				{Key: "user_id", Value: userID},
+				{Key: "name", Value: name},
+			}).
+			SetUpdate(bson.D{
+				{Key: "$setOnInsert", Value: bson.D{{Key: "created_at", Value: now}}},
+			}).
+			SetUpsert(true))
+	}
+
+	_, err := tag.DB.Collection("tag").BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		// a concurrent request may create the same tag, which is fine
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+func TagRepo(db *mongo.Database) tagRepoInterface {
+	return &tagRepo{
+		DB: db,
+	}
+}
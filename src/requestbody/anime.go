@@ -1,9 +1,50 @@
 package requestbody
 
 type Anime struct {
-	Name        string   `json:"name" validate:"required"`
-	Genre       []string `json:"genre" validate:"required,min=1"`
-	Description string   `json:"description" validate:"required"`
-	Image       string   `json:"image" validate:"required"`
-	Status      string   `json:"status" validate:"required"`
+	Name            string   `json:"name" validate:"required"`
+	GenreIds        []string `json:"genre_ids" validate:"required,min=1,dive,mongodb"`
+	Tags            []string `json:"tags" validate:"max=20,dive,required,max=50"`
+	Description     string   `json:"description" validate:"required"`
+	Image           string   `json:"image" validate:"required"`
+	Status          string   `json:"status" validate:"required,anime_status"`
+	TotalEpisodes   int      `json:"total_episodes" validate:"min=0"`
+	WatchedEpisodes int      `json:"watched_episodes" validate:"min=0"`
+}
+
+type Progress struct {
+	Action string `json:"action" validate:"required,oneof=increment decrement set"`
+	Value  *int   `json:"value" validate:"required_if=Action set,omitempty,min=0"`
+}
+
+type AnimeQuery struct {
+	Page   int    `json:"page" validate:"min=1"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Cursor string `json:"cursor" validate:"omitempty,base64url"`
+	Status string `json:"status" validate:"omitempty,anime_status"`
+	Genre  string `json:"genre" validate:"omitempty,mongodb"`
+	Tag    string `json:"tag" validate:"max=50"`
+	Sort   string `json:"sort" validate:"oneof=name created_at score"`
+	Order  string `json:"order" validate:"oneof=asc desc"`
+}
+
+type AnimeSearch struct {
+	Q      string `json:"q" validate:"required,max=200"`
+	Status string `json:"status" validate:"omitempty,anime_status"`
+	Genre  string `json:"genre" validate:"omitempty,mongodb"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+}
+
+type Review struct {
+	Score  *float64 `json:"score" validate:"required_without=Review,omitempty,min=1,max=10,half_point"`
+	Review string   `json:"review" validate:"max=10000"`
+}
+
+type AnimeBulk struct {
+	Operations []AnimeBulkOperation `json:"operations" validate:"required,min=1,max=100"`
+}
+
+type AnimeBulkOperation struct {
+	Op   string `json:"op" validate:"required,oneof=create update delete"`
+	Id   string `json:"id" validate:"required_unless=Op create,excluded_if=Op create,omitempty,mongodb"`
+	Data *Anime `json:"data"`
 }
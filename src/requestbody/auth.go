@@ -1,9 +1,10 @@
 package requestbody
 
 type Register struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"required,min=3,max=30,username"`
+	Email    string `json:"email" validate:"required,email,max=254"`
+	Password string `json:"password" validate:"required"`
+	Language string `json:"language" bson:"language,omitempty" validate:"omitempty,language"`
 }
 
 type Login struct {
@@ -19,3 +20,7 @@ type ResetPassword struct {
 	NewPassword string `json:"newPassword" validate:"required"`
 	Token       string `json:"token" validate:"required"`
 }
+
+type Language struct {
+	Language string `json:"language" validate:"required,language"`
+}
@@ -0,0 +1,99 @@
+package requestbody
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"history_anime/src/apperror"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MaxBodySize is the largest request body Read accepts, in bytes. It defaults
+// to 1 MiB and can be changed with REQUEST_MAX_BODY_SIZE.
+var MaxBodySize int64 = 1 << 20
+
+func init() {
+
+	size, err := strconv.ParseInt(os.Getenv("REQUEST_MAX_BODY_SIZE"), 10, 64)
+	if err == nil && size > 0 {
+		MaxBodySize = size
+	}
+}
+
+// MediaType returns the media type of the request without its parameters, so
+// "application/json; charset=utf-8" is "application/json".
+func MediaType(r *http.Request) string {
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+
+	return mediaType
+}
+
+// Read reads the whole body of r, up to MaxBodySize.
+func Read(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, apperror.ErrBodyTooLarge.With("limit", tooLarge.Limit).Wrap(err)
+	}
+
+	if err != nil {
+		return nil, apperror.ErrBodyUnreadable.Wrap(err)
+	}
+
+	return data, nil
+}
+
+// Unmarshal decodes data into body. Unlike json.Unmarshal it rejects fields
+// body doesn't have and anything after the first json value.
+func Unmarshal(data []byte, body interface{}) error {
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return apperror.ErrBodyEmpty
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(body)
+	if err != nil {
+		// encoding/json has no typed error for unknown fields
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return apperror.ErrUnknownField.With("field", strings.Trim(field, `"`)).Wrap(err)
+		}
+		return apperror.ErrInvalidJSON.Wrap(err)
+	}
+
+	_, err = decoder.Token()
+	if err != io.EOF {
+		return apperror.ErrTrailingData
+	}
+
+	return nil
+}
+
+// Decode reads a json request body into body. The content type must be
+// application/json, parameters like charset are allowed.
+func Decode(w http.ResponseWriter, r *http.Request, body interface{}) error {
+
+	if MediaType(r) != "application/json" {
+		return apperror.ErrContentTypeJSON
+	}
+
+	data, err := Read(w, r)
+	if err != nil {
+		return err
+	}
+
+	return Unmarshal(data, body)
+}
@@ -0,0 +1,9 @@
+package requestbody
+
+import "time"
+
+type Episode struct {
+	Episode   int        `json:"episode" validate:"required,min=1"`
+	WatchedAt *time.Time `json:"watched_at"`
+	Note      string     `json:"note" validate:"max=1000"`
+}
@@ -0,0 +1,5 @@
+package requestbody
+
+type ExportQuery struct {
+	Format string `json:"format" validate:"oneof=json csv mal-xml"`
+}
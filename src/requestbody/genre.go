@@ -1,5 +1,12 @@
 package requestbody
 
 type Genre struct {
-	Name string `json:"name" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	ParentId string `json:"parent_id" validate:"omitempty,mongodb"`
+}
+
+type GenreDelete struct {
+	Id       string `json:"id"`
+	Strategy string `json:"strategy" validate:"omitempty,oneof=detach reassign"`
+	To       string `json:"to" validate:"required_if=Strategy reassign,omitempty,mongodb,nefield=Id"`
 }
@@ -0,0 +1,24 @@
+package requestbody
+
+import "encoding/xml"
+
+// MalExport is the XML file MyAnimeList produces from its "Export" page.
+type MalExport struct {
+	XMLName xml.Name   `xml:"myanimelist"`
+	Anime   []MalAnime `xml:"anime"`
+}
+
+type MalAnime struct {
+	Title           string  `xml:"series_title"`
+	Episodes        int     `xml:"series_episodes"`
+	WatchedEpisodes int     `xml:"my_watched_episodes"`
+	Score           float64 `xml:"my_score"`
+	Status          string  `xml:"my_status"`
+	Tags            string  `xml:"my_tags"`
+}
+
+// Import holds the form fields sent alongside the uploaded file. The MAL
+// export carries no genres, so imported entries get the ones named here.
+type Import struct {
+	Genres []string `json:"genre" validate:"min=1,max=10,dive,required,max=50"`
+}
@@ -0,0 +1,5 @@
+package requestbody
+
+type Tag struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
@@ -3,11 +3,30 @@ package response
 import "history_anime/src/entity"
 
 type AnimeAll struct {
-	Message string         `json:"message"`
-	Data    []entity.Anime `json:"data"`
+	Message    string         `json:"message"`
+	Data       []entity.Anime `json:"data"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"next_cursor"`
 }
 
 type AnimeInsert struct {
 	Message    string `json:"message"`
 	InsertedID string `json:"insertedID"`
 }
+
+type AnimeSearch struct {
+	Message string               `json:"message"`
+	Data    []entity.AnimeSearch `json:"data"`
+}
+
+type AnimeOne struct {
+	Message string       `json:"message"`
+	Data    entity.Anime `json:"data"`
+}
+
+type AnimeBulk struct {
+	Message string                   `json:"message"`
+	Results []entity.AnimeBulkResult `json:"results"`
+}
@@ -0,0 +1,13 @@
+package response
+
+import "history_anime/src/entity"
+
+type EpisodeAll struct {
+	Message string           `json:"message"`
+	Data    []entity.Episode `json:"data"`
+}
+
+type EpisodeInsert struct {
+	Message    string `json:"message"`
+	InsertedID string `json:"insertedID"`
+}
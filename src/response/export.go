@@ -0,0 +1,244 @@
+package response
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"history_anime/src/entity"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AnimeWriter writes anime entries one at a time in a download format.
+// Close must be called once all entries are written.
+type AnimeWriter interface {
+	Write(anime *entity.Anime) error
+	Close() error
+}
+
+type ExportFormat struct {
+	ContentType string
+	Extension   string
+}
+
+var ExportFormats = map[string]ExportFormat{
+	"json":    {ContentType: "application/json", Extension: "json"},
+	"csv":     {ContentType: "text/csv; charset=utf-8", Extension: "csv"},
+	"mal-xml": {ContentType: "application/xml; charset=utf-8", Extension: "xml"},
+}
+
+func NewAnimeWriter(format string, w io.Writer) AnimeWriter {
+
+	switch format {
+	case "csv":
+		return &csvAnimeWriter{w: csv.NewWriter(w)}
+	case "mal-xml":
+		return &malAnimeWriter{w: w, enc: xml.NewEncoder(w)}
+	default:
+		return &jsonAnimeWriter{w: w}
+	}
+}
+
+type jsonAnimeWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (writer *jsonAnimeWriter) Write(anime *entity.Anime) error {
+
+	prefix := ","
+	if !writer.started {
+		prefix = "["
+		writer.started = true
+	}
+
+	data, err := json.Marshal(anime)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.w.Write(append([]byte(prefix), data...))
+	return err
+}
+
+func (writer *jsonAnimeWriter) Close() error {
+
+	end := "]"
+	if !writer.started {
+		end = "[]"
+	}
+
+	_, err := io.WriteString(writer.w, end)
+	return err
+}
+
+var csvHeader = []string{
+	"name", "status", "genre", "tags", "total_episodes", "watched_episodes",
+	"score", "review", "started_at", "completed_at", "created_at",
+}
+
+type csvAnimeWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func (writer *csvAnimeWriter) Write(anime *entity.Anime) error {
+
+	if !writer.started {
+		writer.started = true
+		err := writer.w.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+	}
+
+	score := ""
+	if anime.Score != nil {
+		score = strconv.FormatFloat(*anime.Score, 'f', -1, 64)
+	}
+
+	return writer.w.Write([]string{
+		anime.Name,
+		string(anime.Status),
+		strings.Join(anime.Genre, ";"),
+		strings.Join(anime.Tags, ";"),
+		strconv.Itoa(anime.TotalEpisodes),
+		strconv.Itoa(anime.WatchedEpisodes),
+		score,
+		anime.Review,
+		exportTime(anime.Started_at),
+		exportTime(anime.Completed_at),
+		anime.Created_at.Format(time.RFC3339),
+	})
+}
+
+func (writer *csvAnimeWriter) Close() error {
+
+	if !writer.started {
+		err := writer.w.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.w.Flush()
+	return writer.w.Error()
+}
+
+// malStatusLabels are the my_status values the MyAnimeList importer expects.
+var malStatusLabels = map[entity.AnimeStatus]string{
+	entity.StatusPlanToWatch: "Plan to Watch",
+	entity.StatusWatching:    "Watching",
+	entity.StatusOnHold:      "On-Hold",
+	entity.StatusDropped:     "Dropped",
+	entity.StatusCompleted:   "Completed",
+	entity.StatusRewatching:  "Watching",
+}
+
+type malAnime struct {
+	XMLName         xml.Name `xml:"anime"`
+	Title           cdata    `xml:"series_title"`
+	Episodes        int      `xml:"series_episodes"`
+	WatchedEpisodes int      `xml:"my_watched_episodes"`
+	StartDate       string   `xml:"my_start_date"`
+	FinishDate      string   `xml:"my_finish_date"`
+	Score           int      `xml:"my_score"`
+	Status          string   `xml:"my_status"`
+	Comments        cdata    `xml:"my_comments"`
+	Rewatching      int      `xml:"my_rewatching"`
+	Tags            cdata    `xml:"my_tags"`
+	UpdateOnImport  int      `xml:"update_on_import"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+type malAnimeWriter struct {
+	w       io.Writer
+	enc     *xml.Encoder
+	started bool
+}
+
+func (writer *malAnimeWriter) begin() error {
+
+	writer.started = true
+	_, err := io.WriteString(writer.w, xml.Header+"<myanimelist>\n<myinfo><user_export_type>1</user_export_type></myinfo>\n")
+	return err
+}
+
+func (writer *malAnimeWriter) Write(anime *entity.Anime) error {
+
+	if !writer.started {
+		err := writer.begin()
+		if err != nil {
+			return err
+		}
+	}
+
+	// MyAnimeList only knows whole scores
+	score := 0
+	if anime.Score != nil {
+		score = int(math.Round(*anime.Score))
+	}
+
+	rewatching := 0
+	if anime.Status == entity.StatusRewatching {
+		rewatching = 1
+	}
+
+	err := writer.enc.Encode(malAnime{
+		Title:           cdata{anime.Name},
+		Episodes:        anime.TotalEpisodes,
+		WatchedEpisodes: anime.WatchedEpisodes,
+		StartDate:       malDate(anime.Started_at),
+		FinishDate:      malDate(anime.Completed_at),
+		Score:           score,
+		Status:          malStatusLabels[anime.Status],
+		Comments:        cdata{anime.Review},
+		Rewatching:      rewatching,
+		Tags:            cdata{strings.Join(anime.Tags, ", ")},
+		UpdateOnImport:  1,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(writer.w, "\n")
+	return err
+}
+
+func (writer *malAnimeWriter) Close() error {
+
+	if !writer.started {
+		err := writer.begin()
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(writer.w, "</myanimelist>\n")
+	return err
+}
+
+func exportTime(value *time.Time) string {
+
+	if value == nil {
+		return ""
+	}
+
+	return value.Format(time.RFC3339)
+}
+
+// malDate formats dates the way MyAnimeList does, zeroes meaning unknown.
+func malDate(value *time.Time) string {
+
+	if value == nil {
+		return "0000-00-00"
+	}
+
+	return value.Format(time.DateOnly)
+}
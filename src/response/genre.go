@@ -11,3 +11,13 @@ type GenreInsert struct {
 	Message    string `json:"message"`
 	InsertedID string `json:"insertedID"`
 }
+
+type GenreDelete struct {
+	Message      string `json:"message"`
+	AnimeUpdated int64  `json:"anime_updated"`
+}
+
+type GenreStats struct {
+	Message string              `json:"message"`
+	Data    []entity.GenreStats `json:"data"`
+}
@@ -0,0 +1,11 @@
+package response
+
+import "history_anime/src/entity"
+
+type Import struct {
+	Message string             `json:"message"`
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Skipped int                `json:"skipped"`
+	Rows    []entity.ImportRow `json:"rows"`
+}
@@ -0,0 +1,64 @@
+package response
+
+import (
+	"encoding/json"
+	"history_anime/src/apperror"
+	"history_anime/src/entity"
+	"history_anime/src/i18n"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem document. Code is the stable apperror code,
+// Extensions are merged into the top level object.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail"`
+	Instance   string                 `json:"instance"`
+	Code       string                 `json:"code"`
+	Errors     entity.FieldErrors     `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+
+	type problem Problem
+	res, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return res, err
+	}
+
+	members := map[string]interface{}{}
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	err = json.Unmarshal(res, &members)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
+
+// SendError writes err as application/problem+json, see apperror.As.
+func SendError(w http.ResponseWriter, r *http.Request, err error) {
+
+	appErr := apperror.As(err)
+	lang := i18n.Language(r)
+	res, _ := json.Marshal(Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(appErr.Status),
+		Status:     appErr.Status,
+		Detail:     i18n.T(lang, appErr.Message),
+		Instance:   r.URL.Path,
+		Code:       appErr.Code,
+		Errors:     appErr.Errors.Localize(lang),
+		Extensions: appErr.Extensions,
+	})
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(appErr.Status)
+	w.Write(res)
+}
@@ -1,9 +1,5 @@
 package response
 
-type Errors struct {
-	Errors []string `json:"errors"`
-}
-
 type Msg struct {
 	Message string `json:"message"`
 }
\ No newline at end of file
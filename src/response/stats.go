@@ -0,0 +1,8 @@
+package response
+
+import "history_anime/src/entity"
+
+type Stats struct {
+	Message string       `json:"message"`
+	Data    entity.Stats `json:"data"`
+}
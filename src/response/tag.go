@@ -0,0 +1,13 @@
+package response
+
+import "history_anime/src/entity"
+
+type TagAll struct {
+	Message string       `json:"message"`
+	Data    []entity.Tag `json:"data"`
+}
+
+type TagInsert struct {
+	Message    string `json:"message"`
+	InsertedID string `json:"insertedID"`
+}
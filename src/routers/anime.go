@@ -10,8 +10,22 @@ import (
 func AnimeRoute(anime *httprouter.Router) {
 
 	anime.GET("/api/anime", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimeGetAll)))
+	anime.GET("/api/anime/:id", middlewares.Logging(middlewares.OnlyLogin(staticSegments("id", map[string]httprouter.Handle{
+		"search": controllers.AnimeSearch,
+	}, controllers.AnimeGetByID))))
 	anime.POST("/api/anime", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimeAdd)))
+	anime.POST("/api/anime/:id", middlewares.Logging(middlewares.OnlyLogin(staticSegments("id", map[string]httprouter.Handle{
+		"bulk": controllers.AnimeBulk,
+	}, controllers.NotFound))))
+	anime.POST("/api/anime/:id/progress", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimeProgress)))
 	anime.PUT("/api/anime/:id", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimeUpdate)))
+	anime.PATCH("/api/anime/:id", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimePatch)))
 	anime.DELETE("/api/anime/:id", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimeDel)))
+	anime.PUT("/api/anime/:id/review", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimeReviewSet)))
+	anime.DELETE("/api/anime/:id/review", middlewares.Logging(middlewares.OnlyLogin(controllers.AnimeReviewClear)))
+	anime.GET("/api/anime/:id/episodes", middlewares.Logging(middlewares.OnlyLogin(controllers.EpisodeGetAll)))
+	anime.POST("/api/anime/:id/episodes", middlewares.Logging(middlewares.OnlyLogin(controllers.EpisodeAdd)))
+	anime.PUT("/api/anime/:id/episodes/:episodeId", middlewares.Logging(middlewares.OnlyLogin(controllers.EpisodeUpdate)))
+	anime.DELETE("/api/anime/:id/episodes/:episodeId", middlewares.Logging(middlewares.OnlyLogin(controllers.EpisodeDel)))
 
 }
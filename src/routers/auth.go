@@ -15,4 +15,5 @@ func AuthRoute(auth *httprouter.Router) {
 	auth.POST("/api/forgot-password", middlewares.Logging(controllers.ForgotPassword))
 	auth.POST("/api/reset-password", middlewares.Logging(controllers.ResetPassword))
 	auth.GET("/api/islogin", middlewares.Logging(controllers.IsLogin))
+	auth.PUT("/api/language", middlewares.Logging(middlewares.OnlyLogin(controllers.LanguageUpdate)))
 }
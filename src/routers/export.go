@@ -0,0 +1,13 @@
+package routers
+
+import (
+	"history_anime/src/controllers"
+	"history_anime/src/middlewares"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func ExportRoute(export *httprouter.Router) {
+
+	export.GET("/api/export", middlewares.Logging(middlewares.OnlyLogin(controllers.Export)))
+}
@@ -10,6 +10,8 @@ import (
 func GenreRoute(genre *httprouter.Router) {
 
 	genre.GET("/api/genre", middlewares.Logging(middlewares.OnlyLogin(controllers.GenreGetAll)))
+	genre.GET("/api/genre/stats", middlewares.Logging(middlewares.OnlyLogin(controllers.GenreStats)))
 	genre.POST("/api/genre", middlewares.Logging(middlewares.OnlyLogin(controllers.GenreAdd)))
+	genre.PUT("/api/genre/:id", middlewares.Logging(middlewares.OnlyLogin(controllers.GenreUpdate)))
 	genre.DELETE("/api/genre/:id", middlewares.Logging(middlewares.OnlyLogin(controllers.GenreDelete)))
 }
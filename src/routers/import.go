@@ -0,0 +1,13 @@
+package routers
+
+import (
+	"history_anime/src/controllers"
+	"history_anime/src/middlewares"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func ImportRoute(imports *httprouter.Router) {
+
+	imports.POST("/api/import/mal", middlewares.Logging(middlewares.OnlyLogin(controllers.ImportMal)))
+}
@@ -16,6 +16,23 @@ func Router() *httprouter.Router {
 	AuthRoute(routers)
 	AnimeRoute(routers)
 	GenreRoute(routers)
+	TagRoute(routers)
+	StatsRoute(routers)
+	ImportRoute(routers)
+	ExportRoute(routers)
 
 	return routers
 }
+
+// staticSegments routes fixed path segments such as /api/anime/search that share
+// a position with a wildcard, because httprouter cannot register both directly.
+func staticSegments(param string, static map[string]httprouter.Handle, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+		if handle, ok := static[params.ByName(param)]; ok {
+			handle(w, r, params)
+			return
+		}
+
+		next(w, r, params)
+	}
+}
@@ -0,0 +1,13 @@
+package routers
+
+import (
+	"history_anime/src/controllers"
+	"history_anime/src/middlewares"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func StatsRoute(stats *httprouter.Router) {
+
+	stats.GET("/api/stats", middlewares.Logging(middlewares.OnlyLogin(controllers.StatsGet)))
+}
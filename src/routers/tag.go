@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"history_anime/src/controllers"
+	"history_anime/src/middlewares"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TagRoute(tag *httprouter.Router) {
+
+	tag.GET("/api/tag", middlewares.Logging(middlewares.OnlyLogin(controllers.TagGetAll)))
+	tag.POST("/api/tag", middlewares.Logging(middlewares.OnlyLogin(controllers.TagAdd)))
+	tag.PUT("/api/tag/:id", middlewares.Logging(middlewares.OnlyLogin(controllers.TagUpdate)))
+	tag.DELETE("/api/tag/:id", middlewares.Logging(middlewares.OnlyLogin(controllers.TagDel)))
+}
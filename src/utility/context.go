@@ -0,0 +1,19 @@
+package utility
+
+import (
+	"context"
+	"history_anime/src/entity"
+)
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+func WithUser(ctx context.Context, user entity.Users) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+func UserFromContext(ctx context.Context) (entity.Users, bool) {
+	user, ok := ctx.Value(userContextKey).(entity.Users)
+	return user, ok
+}
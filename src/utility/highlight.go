@@ -0,0 +1,40 @@
+package utility
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// SearchTerms splits a $text search string into the plain words it matches on,
+// dropping negated terms and phrase quotes.
+func SearchTerms(q string) []string {
+
+	terms := []string{}
+	for _, term := range strings.Fields(strings.ReplaceAll(q, `"`, " ")) {
+		if strings.HasPrefix(term, "-") {
+			continue
+		}
+		terms = append(terms, term)
+	}
+
+	return terms
+}
+
+// Highlight escapes text for HTML and wraps every case-insensitive occurrence
+// of the given terms in <mark> tags.
+func Highlight(text string, terms []string) string {
+
+	escaped := html.EscapeString(text)
+	if len(terms) == 0 {
+		return escaped
+	}
+
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, regexp.QuoteMeta(html.EscapeString(term)))
+	}
+
+	re := regexp.MustCompile(`(?i)(` + strings.Join(quoted, "|") + `)`)
+	return re.ReplaceAllString(escaped, "<mark>$1</mark>")
+}
@@ -0,0 +1,353 @@
+package utility
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to doc. It also returns the
+// top-level members the patch touches.
+func MergePatch(doc []byte, patch []byte) ([]byte, []string, error) {
+
+	var target interface{}
+	err := json.Unmarshal(doc, &target)
+	if err != nil {
+		return nil, nil, errors.New(err.Error())
+	}
+
+	var patchValue interface{}
+	err = json.Unmarshal(patch, &patchValue)
+	if err != nil {
+		return nil, nil, errors.New(err.Error())
+	}
+
+	patchObj, ok := patchValue.(map[string]interface{})
+	if !ok {
+		return nil, nil, errors.New("merge patch must be a json object")
+	}
+
+	fields := []string{}
+	for key := range patchObj {
+		fields = append(fields, key)
+	}
+
+	result, err := json.Marshal(mergeValue(target, patchValue))
+	if err != nil {
+		return nil, nil, errors.New(err.Error())
+	}
+
+	return result, fields, nil
+}
+
+func mergeValue(target interface{}, patch interface{}) interface{} {
+
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergeValue(targetObj[key], value)
+	}
+
+	return targetObj
+}
+
+// JSONPatch applies an RFC 6902 JSON Patch to doc. It also returns the
+// top-level members the operations touch.
+func JSONPatch(doc []byte, patch []byte) ([]byte, []string, error) {
+
+	var target interface{}
+	err := json.Unmarshal(doc, &target)
+	if err != nil {
+		return nil, nil, errors.New(err.Error())
+	}
+
+	operations := []PatchOperation{}
+	err = json.Unmarshal(patch, &operations)
+	if err != nil {
+		return nil, nil, errors.New("json patch must be an array of operations")
+	}
+
+	touched := map[string]bool{}
+	fields := []string{}
+	touch := func(path string) error {
+		tokens, err := parsePointer(path)
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			return errors.New("json patch cannot replace the whole document")
+		}
+		if !touched[tokens[0]] {
+			touched[tokens[0]] = true
+			fields = append(fields, tokens[0])
+		}
+		return nil
+	}
+
+	for i, operation := range operations {
+		target, err = applyOperation(target, operation, touch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("operation %d: %s", i, err.Error())
+		}
+	}
+
+	result, err := json.Marshal(target)
+	if err != nil {
+		return nil, nil, errors.New(err.Error())
+	}
+
+	return result, fields, nil
+}
+
+func applyOperation(doc interface{}, operation PatchOperation, touch func(path string) error) (interface{}, error) {
+
+	path, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if operation.Op == "add" || operation.Op == "replace" || operation.Op == "test" {
+		if operation.Value == nil {
+			return nil, fmt.Errorf("'%s' requires a value", operation.Op)
+		}
+		err := json.Unmarshal(operation.Value, &value)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+	}
+
+	switch operation.Op {
+	case "add", "replace", "remove":
+		err := touch(operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		return mutate(doc, path, operation.Op, value)
+
+	case "move", "copy":
+		from, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if operation.Op == "move" {
+			if strings.HasPrefix(operation.Path+"/", operation.From+"/") && operation.Path != operation.From {
+				return nil, errors.New("cannot move a value into one of its children")
+			}
+			err = touch(operation.From)
+			if err != nil {
+				return nil, err
+			}
+			doc, err = mutate(doc, from, "remove", nil)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			value = deepCopy(value)
+		}
+
+		err = touch(operation.Path)
+		if err != nil {
+			return nil, err
+		}
+		return mutate(doc, path, "add", value)
+
+	case "test":
+		current, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, fmt.Errorf("test failed for path '%s'", operation.Path)
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unknown op '%s'", operation.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path '%s' must start with '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func pointerGet(doc interface{}, tokens []string) (interface{}, error) {
+
+	node := doc
+	for _, token := range tokens {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path member '%s' not found", token)
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, fmt.Errorf("path member '%s' not found", token)
+		}
+	}
+
+	return node, nil
+}
+
+func mutate(node interface{}, tokens []string, op string, value interface{}) (interface{}, error) {
+
+	if len(tokens) == 0 {
+		if op == "remove" {
+			return nil, errors.New("cannot remove the whole document")
+		}
+		return value, nil
+	}
+
+	token := tokens[0]
+	last := len(tokens) == 1
+
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, exists := container[token]
+		if !last {
+			if !exists {
+				return nil, fmt.Errorf("path member '%s' not found", token)
+			}
+			updated, err := mutate(child, tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			container[token] = updated
+			return container, nil
+		}
+
+		if op != "add" && !exists {
+			return nil, fmt.Errorf("path member '%s' not found", token)
+		}
+		if op == "remove" {
+			delete(container, token)
+		} else {
+			container[token] = value
+		}
+		return container, nil
+
+	case []interface{}:
+		if !last {
+			index, err := arrayIndex(token, len(container), false)
+			if err != nil {
+				return nil, err
+			}
+			updated, err := mutate(container[index], tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+			container[index] = updated
+			return container, nil
+		}
+
+		index, err := arrayIndex(token, len(container), op == "add")
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case "add":
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+		case "replace":
+			container[index] = value
+		case "remove":
+			container = append(container[:index], container[index+1:]...)
+		}
+		return container, nil
+	}
+
+	return nil, fmt.Errorf("path member '%s' not found", token)
+}
+
+func arrayIndex(token string, length int, insert bool) (int, error) {
+
+	if insert && token == "-" {
+		return length, nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("array index '%s' invalid", token)
+	}
+
+	max := length - 1
+	if insert {
+		max = length
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index '%s' out of range", token)
+	}
+
+	return index, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = deepCopy(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopy(item)
+		}
+		return result
+	}
+
+	return value
+}
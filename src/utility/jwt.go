@@ -28,7 +28,7 @@ func VerifyToken(key string, token string) (string, error) {
 	})
 
 	if err != nil {
-		return "", errors.New(err.Error())
+		return "", err
 	}
 
 	if !tokenv.Valid {
@@ -44,11 +44,14 @@ func VerifyToken(key string, token string) (string, error) {
 	return id, nil
 }
 
+// ForgotPasswordTTL is how long a reset password link stays valid.
+const ForgotPasswordTTL = time.Minute * 10
+
 func CreateTokenForgotPassword(key string, email string) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"email": email,
-		"exp":   time.Now().Add(time.Minute * 10).Unix(),
+		"exp":   time.Now().Add(ForgotPasswordTTL).Unix(),
 	})
 
 	tokenString, err := token.SignedString([]byte(key))
@@ -65,7 +68,7 @@ func VerifyTokenForgotPassword(key string, token string) (string, error) {
 	})
 
 	if err != nil {
-		return "", errors.New(err.Error())
+		return "", err
 	}
 
 	if !tokenv.Valid {
@@ -1,26 +1,198 @@
 package validation
 
 import (
-	"fmt"
+	"history_anime/src/entity"
 	"history_anime/src/requestbody"
-
-	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
-func ValidateAnime(body *requestbody.Anime) []string {
+func ValidateAnime(body *requestbody.Anime) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(body)
 
-	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err != nil {
+
+		errResult = fieldErrors(err)
+
+		return errResult
+	}
 
-	errResult := []string{}
+	return ValidateProgressRange(body.WatchedEpisodes, body.TotalEpisodes)
+
+}
+
+// ValidateReview requires a score, a review or both. Scores run from 1 to 10
+// in half points.
+func ValidateReview(body *requestbody.Review) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
 	err := validate.Struct(body)
 
 	if err != nil {
 
-		for _, err := range err.(validator.ValidationErrors) {
-			errResult = append(errResult, fmt.Sprintf("Error:Field validation for '%s' failed on the 'required' tag", err.Field()))
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
+
+func ValidateAnimeQuery(query *requestbody.AnimeQuery) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(query)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
+
+func ValidateAnimeSearch(query *requestbody.AnimeSearch) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(query)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
+
+// ValidateAnimePartial validates only the fields a PATCH request touched.
+// fields are json names; members that are not part of requestbody.Anime are rejected.
+func ValidateAnimePartial(body *requestbody.Anime, fields []string) entity.FieldErrors {
+
+	structFields := map[string]string{}
+	typ := reflect.TypeOf(*body)
+	for i := 0; i < typ.NumField(); i++ {
+		jsonName := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		structFields[jsonName] = typ.Field(i).Name
+	}
+
+	errResult := entity.FieldErrors{}
+	partial := []string{}
+	for _, field := range fields {
+		name, ok := structFields[field]
+		if !ok {
+			errResult = append(errResult, NewFieldError(field, "patchable", "", "patchable"))
+			continue
+		}
+		partial = append(partial, name)
+	}
+
+	if len(errResult) > 0 || len(partial) == 0 {
+		return errResult
+	}
+
+	err := validate.StructPartial(body, partial...)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+
+		return errResult
+	}
+
+	for _, name := range partial {
+		if name == "TotalEpisodes" || name == "WatchedEpisodes" {
+			return ValidateProgressRange(body.WatchedEpisodes, body.TotalEpisodes)
 		}
 	}
 
 	return errResult
+}
 
+func ValidateStatusTransition(current entity.AnimeStatus, next entity.AnimeStatus) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	if current.CanTransitionTo(next) {
+		return errResult
+	}
+
+	allowed := []string{}
+	for _, status := range current.NextStatuses() {
+		allowed = append(allowed, string(status))
+	}
+
+	errResult = append(errResult, NewFieldError("status", "status_transition", strings.Join(allowed, " "), "status_transition", string(current), string(next), strings.Join(allowed, ", ")))
+	return errResult
+}
+
+func ValidateProgress(body *requestbody.Progress) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(body)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
+
+// ValidateProgressRange keeps watched episodes within 0..total.
+// A total of 0 means the episode count is not known yet.
+func ValidateProgressRange(watched int, total int) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	if watched < 0 {
+		errResult = append(errResult, NewFieldError("watched_episodes", "min", "0", "min-number", "0"))
+	} else if total > 0 && watched > total {
+		errResult = append(errResult, NewFieldError("watched_episodes", "max", strconv.Itoa(total), "max-number", strconv.Itoa(total)))
+	}
+
+	return errResult
+}
+
+func ValidateAnimeBulk(body *requestbody.AnimeBulk) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(body)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
+
+// ValidateAnimeBulkOperation checks a single bulk operation. Data is required
+// for create and update and validated like the body of AnimeAdd.
+func ValidateAnimeBulkOperation(op *requestbody.AnimeBulkOperation) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.StructExcept(op, "Data")
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+
+		return errResult
+	}
+
+	if op.Op == "delete" {
+		return errResult
+	}
+
+	if op.Data == nil {
+		errResult = append(errResult, NewFieldError("data", "required_unless", "op delete", "required"))
+		return errResult
+	}
+
+	// report the fields of data by their path in the operation
+	errResult = ValidateAnime(op.Data)
+	for i := range errResult {
+		errResult[i].Field = "data." + errResult[i].Field
+	}
+
+	return errResult
 }
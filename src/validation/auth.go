@@ -1,57 +1,79 @@
 package validation
 
 import (
-	"fmt"
+	"history_anime/src/entity"
 	"history_anime/src/requestbody"
-
-	"github.com/go-playground/validator/v10"
+	"regexp"
 )
 
-func ValidateLogin(body *requestbody.Login) []string {
-
-	validate := validator.New(validator.WithRequiredStructEnabled())
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
 
-	errResult := []string{}
+// ValidateRegister checks the email and username format and runs the password
+// through the configured PasswordPolicy.
+func ValidateRegister(body *requestbody.Register) entity.FieldErrors {
 
+	errResult := entity.FieldErrors{}
 	err := validate.Struct(body)
+
 	if err != nil {
 
-		for _, err := range err.(validator.ValidationErrors) {
-			errResult = append(errResult, fmt.Sprintf("Error:Field validation for '%s' failed on the 'required' tag", err.Field()))
-		}
+		errResult = fieldErrors(err)
 	}
 
-	return errResult
+	if body.Password != "" {
+		errResult = append(errResult, ValidatePassword("password", body.Password, PasswordPolicyFromEnv())...)
+	}
 
+	return errResult
 }
 
-func ValidateForgotPassword(body *requestbody.ForgotPassword) []string {
-	validate := validator.New(validator.WithRequiredStructEnabled())
+func ValidateLogin(body *requestbody.Login) entity.FieldErrors {
 
-	errResult := []string{}
+	errResult := entity.FieldErrors{}
 
 	err := validate.Struct(body)
 	if err != nil {
 
-		for _, err := range err.(validator.ValidationErrors) {
-			errResult = append(errResult, fmt.Sprintf("Error:Field validation for '%s' failed on the 'required' tag", err.Field()))
-		}
+		errResult = fieldErrors(err)
 	}
 
 	return errResult
+
 }
 
-func ValidateResetPassword(body *requestbody.ResetPassword) []string {
-	validate := validator.New(validator.WithRequiredStructEnabled())
+func ValidateForgotPassword(body *requestbody.ForgotPassword) entity.FieldErrors {
+	errResult := entity.FieldErrors{}
+
+	err := validate.Struct(body)
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
 
-	errResult := []string{}
+func ValidateResetPassword(body *requestbody.ResetPassword) entity.FieldErrors {
+	errResult := entity.FieldErrors{}
 	err := validate.Struct(body)
 	if err != nil {
 
-		for _, err := range err.(validator.ValidationErrors) {
-			errResult = append(errResult, fmt.Sprintf("Error:Field validation for '%s' failed on the 'required' tag", err.Field()))
+		errResult = fieldErrors(err)
+
+		return errResult
+	}
+
+	return ValidatePassword("newPassword", body.NewPassword, PasswordPolicyFromEnv())
+}
+
+func ValidateLanguage(body *requestbody.Language) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(body)
+
+	if err != nil {
 
-		}
+		errResult = fieldErrors(err)
 	}
 
 	return errResult
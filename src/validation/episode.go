@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+	"strconv"
+)
+
+// ValidateEpisode checks the log entry itself and, when the series length is
+// known, that the episode number exists.
+func ValidateEpisode(body *requestbody.Episode, totalEpisodes int) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(body)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+
+		return errResult
+	}
+
+	if totalEpisodes > 0 && body.Episode > totalEpisodes {
+		errResult = append(errResult, NewFieldError("episode", "max", strconv.Itoa(totalEpisodes), "max-number", strconv.Itoa(totalEpisodes)))
+	}
+
+	return errResult
+}
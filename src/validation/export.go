@@ -0,0 +1,19 @@
+package validation
+
+import (
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+)
+
+func ValidateExportQuery(query *requestbody.ExportQuery) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(query)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
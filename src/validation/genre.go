@@ -1,26 +1,49 @@
 package validation
 
 import (
-	"fmt"
+	"history_anime/src/entity"
 	"history_anime/src/requestbody"
-
-	"github.com/go-playground/validator/v10"
+	"strings"
 )
 
-func ValidateGenre(body *requestbody.Genre) []string {
+// ValidateGenre trims the name first, like ValidateTag, so "Action " is the
+// same genre as "Action" and a name of only whitespace is rejected.
+func ValidateGenre(body *requestbody.Genre) entity.FieldErrors {
 
-	validate := validator.New(validator.WithRequiredStructEnabled())
+	body.Name = strings.TrimSpace(body.Name)
 
-	errResult := []string{}
+	errResult := entity.FieldErrors{}
 	err := validate.Struct(body)
 
 	if err != nil {
 
-		for _, err := range err.(validator.ValidationErrors) {
-			errResult = append(errResult, fmt.Sprintf("Error:Field validation for '%s' failed on the 'required' tag", err.Field()))
-		}
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+
+}
+
+func ValidateGenreDelete(query *requestbody.GenreDelete) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(query)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
 	}
 
 	return errResult
+}
 
+// ValidateGenreRefs turns the genre ids missing from the genre collection into errors.
+func ValidateGenreRefs(missing []string) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	for _, id := range missing {
+		errResult = append(errResult, NewFieldError("genre_ids", "exists", id, "genre_exists", id))
+	}
+
+	return errResult
 }
@@ -0,0 +1,19 @@
+package validation
+
+import (
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+)
+
+func ValidateImport(body *requestbody.Import) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(body)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
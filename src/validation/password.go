@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"bufio"
+	_ "embed"
+	"history_anime/src/entity"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = func() map[string]bool {
+
+	passwords := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(commonPasswordList))
+	for scanner.Scan() {
+		if password := strings.TrimSpace(scanner.Text()); password != "" {
+			passwords[password] = true
+		}
+	}
+
+	return passwords
+}()
+
+// PasswordPolicy is read from the environment so deployments can tighten or
+// relax it without a release:
+//
+//	PASSWORD_MIN_LENGTH      default 8
+//	PASSWORD_REQUIRE_UPPER   default true
+//	PASSWORD_REQUIRE_LOWER   default true
+//	PASSWORD_REQUIRE_DIGIT   default true
+//	PASSWORD_REQUIRE_SYMBOL  default false
+//	PASSWORD_REJECT_COMMON   default true
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectCommon  bool
+}
+
+// bcrypt ignores everything past 72 bytes
+const passwordMaxLength = 72
+
+func PasswordPolicyFromEnv() PasswordPolicy {
+
+	return PasswordPolicy{
+		MinLength:     envInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:  envBool("PASSWORD_REQUIRE_UPPER", true),
+		RequireLower:  envBool("PASSWORD_REQUIRE_LOWER", true),
+		RequireDigit:  envBool("PASSWORD_REQUIRE_DIGIT", true),
+		RequireSymbol: envBool("PASSWORD_REQUIRE_SYMBOL", false),
+		RejectCommon:  envBool("PASSWORD_REJECT_COMMON", true),
+	}
+}
+
+func envInt(key string, fallback int) int {
+
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+func envBool(key string, fallback bool) bool {
+
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// ValidatePassword checks password against the policy and reports every rule
+// it breaks, using field as the name in the messages.
+func ValidatePassword(field string, password string, policy PasswordPolicy) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	if len(password) > passwordMaxLength {
+		errResult = append(errResult, NewFieldError(field, "max", strconv.Itoa(passwordMaxLength), "password_max", strconv.Itoa(passwordMaxLength)))
+	}
+
+	if len([]rune(password)) < policy.MinLength {
+		errResult = append(errResult, NewFieldError(field, "min", strconv.Itoa(policy.MinLength), "password_min", strconv.Itoa(policy.MinLength)))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			upper = true
+		case unicode.IsLower(char):
+			lower = true
+		case unicode.IsDigit(char):
+			digit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			symbol = true
+		}
+	}
+
+	if policy.RequireUpper && !upper {
+		errResult = append(errResult, NewFieldError(field, "contains_upper", "", "contains_upper"))
+	}
+	if policy.RequireLower && !lower {
+		errResult = append(errResult, NewFieldError(field, "contains_lower", "", "contains_lower"))
+	}
+	if policy.RequireDigit && !digit {
+		errResult = append(errResult, NewFieldError(field, "contains_digit", "", "contains_digit"))
+	}
+	if policy.RequireSymbol && !symbol {
+		errResult = append(errResult, NewFieldError(field, "contains_symbol", "", "contains_symbol"))
+	}
+
+	if policy.RejectCommon && commonPasswords[strings.ToLower(password)] {
+		errResult = append(errResult, NewFieldError(field, "not_common", "", "not_common"))
+	}
+
+	return errResult
+}
@@ -0,0 +1,26 @@
+package validation
+
+import (
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+)
+
+func ValidateStatsQuery(query *requestbody.StatsQuery) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(query)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+
+		return errResult
+	}
+
+	// both are YYYY-MM-DD so they compare as strings
+	if query.From != "" && query.To != "" && query.From > query.To {
+		errResult = append(errResult, NewFieldError("from", "ltefield", "to", "ltefield", "to"))
+	}
+
+	return errResult
+}
@@ -0,0 +1,30 @@
+package validation
+
+import (
+	"history_anime/src/entity"
+	"history_anime/src/requestbody"
+	"strings"
+)
+
+// ValidateTag trims the name first, the repository stores it trimmed so a
+// name of only whitespace would otherwise become an empty tag.
+func ValidateTag(body *requestbody.Tag) entity.FieldErrors {
+
+	body.Name = strings.TrimSpace(body.Name)
+
+	errResult := entity.FieldErrors{}
+	err := validate.Struct(body)
+
+	if err != nil {
+
+		errResult = fieldErrors(err)
+	}
+
+	return errResult
+}
+
+// ValidateTags checks tags that don't arrive in a request body, like the ones
+// of an import, against the tag rules of requestbody.Anime.
+func ValidateTags(tags []string) entity.FieldErrors {
+	return ValidateAnimePartial(&requestbody.Anime{Tags: tags}, []string{"tags"})
+}
@@ -0,0 +1,153 @@
+package validation
+
+import (
+	"history_anime/src/entity"
+	"history_anime/src/i18n"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+var (
+	validate    *validator.Validate
+	translators = map[string]ut.Translator{}
+)
+
+func init() {
+
+	validate = validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	validate.RegisterValidation("anime_status", func(fl validator.FieldLevel) bool {
+		return entity.AnimeStatus(fl.Field().String()).IsValid()
+	})
+	validate.RegisterValidation("half_point", func(fl validator.FieldLevel) bool {
+		doubled := fl.Field().Float() * 2
+		return doubled == math.Trunc(doubled)
+	})
+	validate.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		return usernamePattern.MatchString(fl.Field().String())
+	})
+	validate.RegisterValidation("language", func(fl validator.FieldLevel) bool {
+		return i18n.Supported(fl.Field().String())
+	})
+
+	english := en.New()
+	uni := ut.New(english, english, id.New())
+
+	translators["en"], _ = uni.GetTranslator("en")
+	translators["id"], _ = uni.GetTranslator("id")
+	mustRegister(en_translations.RegisterDefaultTranslations(validate, translators["en"]))
+	mustRegister(id_translations.RegisterDefaultTranslations(validate, translators["id"]))
+
+	// our own tags and the checks done outside the validator come from the
+	// i18n catalogues
+	for _, lang := range i18n.Languages {
+		trans := translators[lang]
+		for tag, text := range i18n.Get(lang).ValidationTags {
+			tag, text := tag, text
+			mustRegister(validate.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+				return trans.Add(tag, text, true)
+			}, func(trans ut.Translator, fe validator.FieldError) string {
+				message, _ := trans.T(fe.Tag(), fe.Field(), ruleParam(fe))
+				return message
+			}))
+		}
+
+		for key, text := range i18n.Get(lang).ValidationChecks {
+			mustRegister(trans.Add(key, text, false))
+		}
+	}
+}
+
+func mustRegister(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ruleParam is the parameter reported for a failed tag. anime_status and
+// language have none of their own, so the allowed values are reported instead.
+func ruleParam(fe validator.FieldError) string {
+
+	switch fe.Tag() {
+	case "anime_status":
+		statuses := []string{}
+		for _, status := range entity.AnimeStatuses {
+			statuses = append(statuses, string(status))
+		}
+		return strings.Join(statuses, " ")
+	case "language":
+		return strings.Join(i18n.Languages, " ")
+	}
+
+	return fe.Param()
+}
+
+// fieldErrors converts the result of validate.Struct and friends. Fields are
+// reported by their json path without the name of the validated struct.
+func fieldErrors(err error) entity.FieldErrors {
+
+	errResult := entity.FieldErrors{}
+	for _, fe := range err.(validator.ValidationErrors) {
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+
+		messages := map[string]string{}
+		for _, lang := range i18n.Languages {
+			trans := translators[lang]
+			message := fe.Translate(trans)
+			if message == fe.Error() {
+				message, _ = trans.T("fallback", fe.Field(), fe.Tag())
+			}
+			messages[lang] = message
+		}
+
+		errResult = append(errResult, entity.FieldError{
+			Field:    field,
+			Rule:     fe.Tag(),
+			Param:    ruleParam(fe),
+			Message:  messages[i18n.Languages[0]],
+			Messages: messages,
+		})
+	}
+
+	return errResult
+}
+
+// NewFieldError builds an error for a check the validator can't express. key
+// picks the message, which gets field followed by args.
+func NewFieldError(field string, rule string, param string, key string, args ...string) entity.FieldError {
+
+	messages := map[string]string{}
+	for _, lang := range i18n.Languages {
+		message, err := translators[lang].T(key, append([]string{field}, args...)...)
+		if err != nil {
+			message = key
+		}
+		messages[lang] = message
+	}
+
+	return entity.FieldError{
+		Field:    field,
+		Rule:     rule,
+		Param:    param,
+		Message:  messages[i18n.Languages[0]],
+		Messages: messages,
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestAnimeAdd(t *testing.T) {
@@ -21,7 +22,7 @@ func TestAnimeAdd(t *testing.T) {
 		dataInsert := requestbody.Anime{
 			Name:        "testing",
 			Description: "lorem",
-			Genre:       []string{"testing"},
+			GenreIds:    []string{GenreID},
 			Image:       "https://example.com",
 			Status:      "watching",
 		}
@@ -70,7 +71,7 @@ func TestAnimeAdd(t *testing.T) {
 		data := requestbody.Anime{
 			Name:        "",
 			Description: "",
-			Genre:       []string{},
+			GenreIds:    []string{},
 			Image:       "",
 			Status:      "",
 		}
@@ -102,7 +103,7 @@ func TestAnimeAdd(t *testing.T) {
 		resBodyByte, err := io.ReadAll(body)
 		require.Nil(t, err)
 
-		resBody := response.Errors{}
+		resBody := response.Problem{}
 		err = json.Unmarshal(resBodyByte, &resBody)
 		require.Nil(t, err)
 
@@ -117,7 +118,7 @@ func TestAnimeAdd(t *testing.T) {
 		dataInsert := requestbody.Anime{
 			Name:        "testing",
 			Description: "lorem",
-			Genre:       []string{"testing"},
+			GenreIds:    []string{GenreID},
 			Image:       "https://example.com",
 			Status:      "watching",
 		}
@@ -148,36 +149,37 @@ func TestAnimeAdd(t *testing.T) {
 		resBodyByte, err := io.ReadAll(body)
 		require.Nil(t, err)
 
-		resBody := response.Errors{}
+		resBody := response.Problem{}
 
 		err = json.Unmarshal(resBodyByte, &resBody)
 		require.Nil(t, err)
 
-		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "content-type must be application/json", resBody.Errors[0])
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+		assert.Equal(t, "CONTENT_TYPE_INVALID", resBody.Code)
+		assert.Equal(t, "content-type must be application/json", resBody.Detail)
 	})
 }
 
 func TestAnimeUpdate(t *testing.T) {
 	data := requestbody.Anime{
 		Name:        "testing",
-		Genre:       []string{"testing"},
+		GenreIds:    []string{GenreID},
 		Description: "testingubah",
 		Image:       "https://history.com",
-		Status:      "finish",
+		Status:      "watching",
 	}
 
-	id, err := dbutility.AnimeAdd(data.Name, data.Image, data.Genre, data.Description, data.Status)
+	id, err := dbutility.AnimeAdd(UserID, data.Name, data.Image, data.GenreIds, data.Description, data.Status)
 	require.Nil(t, err)
 
 	t.Run("success", func(t *testing.T) {
 
 		data := requestbody.Anime{
 			Name:        "testingubah",
-			Genre:       []string{"testingubah"},
+			GenreIds:    []string{GenreID},
 			Description: "testingubah",
 			Image:       "https://history.com",
-			Status:      "finish",
+			Status:      "completed",
 		}
 
 		bodyByte, err := json.Marshal(data)
@@ -219,7 +221,7 @@ func TestAnimeUpdate(t *testing.T) {
 
 		data := requestbody.Anime{
 			Name:        "",
-			Genre:       []string{},
+			GenreIds:    []string{},
 			Description: "",
 			Image:       "",
 			Status:      "",
@@ -252,7 +254,7 @@ func TestAnimeUpdate(t *testing.T) {
 		resBodyRead, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		resBodyJson := response.Errors{}
+		resBodyJson := response.Problem{}
 		err = json.Unmarshal(resBodyRead, &resBodyJson)
 		require.Nil(t, err)
 
@@ -260,14 +262,61 @@ func TestAnimeUpdate(t *testing.T) {
 		assert.Equal(t, 5, len(resBodyJson.Errors))
 	})
 
-	t.Run("content type error", func(t *testing.T) {
+	t.Run("validation error status value", func(t *testing.T) {
+
+		data := requestbody.Anime{
+			Name:        "testingubah",
+			GenreIds:    []string{GenreID},
+			Description: "testingubah",
+			Image:       "https://history.com",
+			Status:      "on going",
+		}
+
+		bodyByte, err := json.Marshal(data)
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/anime/"+id, bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyRead, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyRead, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, resBodyJson.Errors)
+		assert.Equal(t, "status", resBodyJson.Errors[0].Field)
+		assert.Equal(t, "anime_status", resBodyJson.Errors[0].Rule)
+	})
+
+	t.Run("validation error status transition", func(t *testing.T) {
 
 		data := requestbody.Anime{
 			Name:        "testingubah",
-			Genre:       []string{"testingubah"},
+			GenreIds:    []string{GenreID},
 			Description: "testingubah",
 			Image:       "https://history.com",
-			Status:      "finish",
+			Status:      "on-hold",
 		}
 
 		bodyByte, err := json.Marshal(data)
@@ -276,6 +325,7 @@ func TestAnimeUpdate(t *testing.T) {
 		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/anime/"+id, bytes.NewReader(bodyByte))
 		require.Nil(t, err)
 
+		request.Header.Set("Content-Type", "application/json")
 		request.AddCookie(&http.Cookie{
 			Name:     "token",
 			Value:    TokenUser,
@@ -296,12 +346,60 @@ func TestAnimeUpdate(t *testing.T) {
 		resBodyRead, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		resBodyJson := response.Errors{}
+		resBodyJson := response.Problem{}
 		err = json.Unmarshal(resBodyRead, &resBodyJson)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "content-type must be application/json", resBodyJson.Errors[0])
+		require.NotEmpty(t, resBodyJson.Errors)
+		assert.Equal(t, "status", resBodyJson.Errors[0].Field)
+		assert.Equal(t, "status_transition", resBodyJson.Errors[0].Rule)
+		assert.Equal(t, "rewatching", resBodyJson.Errors[0].Param)
+	})
+
+	t.Run("content type error", func(t *testing.T) {
+
+		data := requestbody.Anime{
+			Name:        "testingubah",
+			GenreIds:    []string{GenreID},
+			Description: "testingubah",
+			Image:       "https://history.com",
+			Status:      "completed",
+		}
+
+		bodyByte, err := json.Marshal(data)
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/anime/"+id, bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyRead, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyRead, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+		assert.Equal(t, "CONTENT_TYPE_INVALID", resBodyJson.Code)
+		assert.Equal(t, "content-type must be application/json", resBodyJson.Detail)
 	})
 
 	err = dbutility.AnimeDeleteOneById(id)
@@ -314,11 +412,11 @@ func TestAnimeDelete(t *testing.T) {
 		dataInsert := requestbody.Anime{
 			Name:        "testing",
 			Description: "lorem",
-			Genre:       []string{"testing"},
+			GenreIds:    []string{GenreID},
 			Image:       "https://example.com",
 			Status:      "watching",
 		}
-		id, err := dbutility.AnimeAdd(dataInsert.Name, dataInsert.Image, dataInsert.Genre, dataInsert.Description, dataInsert.Status)
+		id, err := dbutility.AnimeAdd(UserID, dataInsert.Name, dataInsert.Image, dataInsert.GenreIds, dataInsert.Description, dataInsert.Status)
 		require.Nil(t, err)
 
 		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/anime/"+id, nil)
@@ -376,13 +474,58 @@ func TestAnimeDelete(t *testing.T) {
 		bodyByte, err := io.ReadAll(body)
 		require.Nil(t, err)
 
-		bodyJson := response.Errors{}
+		bodyJson := response.Problem{}
+
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+		assert.Equal(t, "about:blank", bodyJson.Type)
+		assert.Equal(t, "Not Found", bodyJson.Title)
+		assert.Equal(t, http.StatusNotFound, bodyJson.Status)
+		assert.Equal(t, "/api/anime/65c217fc6556430b3dc4ce61", bodyJson.Instance)
+		assert.Equal(t, "ANIME_NOT_FOUND", bodyJson.Code)
+		assert.Equal(t, "anime not found", bodyJson.Detail)
+	})
+
+	t.Run("error other user anime", func(t *testing.T) {
+		otherUserID := primitive.NewObjectID().Hex()
+		id, err := dbutility.AnimeAdd(otherUserID, "testingother", "https://example.com", []string{GenreID}, "lorem", "watching")
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/anime/"+id, nil)
+		require.Nil(t, err)
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
 
 		err = json.Unmarshal(bodyByte, &bodyJson)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusNotFound, res.StatusCode)
-		assert.Equal(t, "anime not found", bodyJson.Errors[0])
+		assert.Equal(t, "anime not found", bodyJson.Detail)
+
+		err = dbutility.AnimeDeleteOneById(id)
+		require.Nil(t, err)
 	})
 
 }
@@ -392,12 +535,12 @@ func TestAnimeGetAllSuccess(t *testing.T) {
 	dataInsert := requestbody.Anime{
 		Name:        "testing1",
 		Description: "lorem",
-		Genre:       []string{"testing1"},
+		GenreIds:    []string{GenreID},
 		Image:       "https://example.com",
 		Status:      "watching",
 	}
 
-	id, err := dbutility.AnimeAdd(dataInsert.Name, dataInsert.Image, dataInsert.Genre, dataInsert.Description, dataInsert.Status)
+	id, err := dbutility.AnimeAdd(UserID, dataInsert.Name, dataInsert.Image, dataInsert.GenreIds, dataInsert.Description, dataInsert.Status)
 	require.Nil(t, err)
 
 	request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime", nil)
@@ -429,8 +572,914 @@ func TestAnimeGetAllSuccess(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 	assert.Equal(t, "all data anime", bodyJson.Message)
+	for _, anime := range bodyJson.Data {
+		assert.Equal(t, UserID, anime.UserId.Hex())
+		if anime.Id.Hex() == id {
+			assert.Equal(t, []string{GenreName}, anime.Genre)
+		}
+	}
+
+	err = dbutility.AnimeDeleteOneById(id)
+	require.Nil(t, err)
+
+}
+
+func TestAnimeGetAllPagination(t *testing.T) {
+
+	firstID, err := dbutility.AnimeAdd(UserID, "testingpage1", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+	secondID, err := dbutility.AnimeAdd(UserID, "testingpage2", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	t.Run("success limit and next cursor", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime?limit=1&sort=name&order=asc", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.AnimeAll{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 1, len(bodyJson.Data))
+		assert.Equal(t, 1, bodyJson.Limit)
+		assert.GreaterOrEqual(t, bodyJson.Total, int64(2))
+		assert.NotEmpty(t, bodyJson.NextCursor)
+	})
+
+	t.Run("validation error sort", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime?sort=status", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, bodyJson.Errors)
+		assert.Equal(t, "sort", bodyJson.Errors[0].Field)
+		assert.Equal(t, "oneof", bodyJson.Errors[0].Rule)
+	})
+
+	err = dbutility.AnimeDeleteOneById(firstID)
+	require.Nil(t, err)
+	err = dbutility.AnimeDeleteOneById(secondID)
+	require.Nil(t, err)
+}
+
+func TestAnimeSearch(t *testing.T) {
+
+	id, err := dbutility.AnimeAdd(UserID, "testingsearch frieren", "https://example.com", []string{GenreID}, "journey after the hero party", "watching")
+	require.Nil(t, err)
+
+	t.Run("success", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/search?q=frieren&status=watching", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.AnimeSearch{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NotEmpty(t, bodyJson.Data)
+		assert.Equal(t, id, bodyJson.Data[0].Id.Hex())
+		assert.Contains(t, bodyJson.Data[0].Highlight.Name, "<mark>frieren</mark>")
+	})
+
+	t.Run("validation error required", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/search", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, bodyJson.Errors)
+		assert.Equal(t, "q", bodyJson.Errors[0].Field)
+		assert.Equal(t, "required", bodyJson.Errors[0].Rule)
+	})
 
 	err = dbutility.AnimeDeleteOneById(id)
 	require.Nil(t, err)
+}
+
+func TestAnimeGetByID(t *testing.T) {
+
+	id, err := dbutility.AnimeAdd(UserID, "testingdetail", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
 
+	var etag string
+
+	t.Run("success", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/"+id, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.AnimeOne{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		etag = res.Header.Get("ETag")
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "detail anime", bodyJson.Message)
+		assert.Equal(t, id, bodyJson.Data.Id.Hex())
+		assert.NotEmpty(t, etag)
+		assert.NotEmpty(t, res.Header.Get("Last-Modified"))
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/"+id, nil)
+		require.Nil(t, err)
+
+		request.Header.Set("If-None-Match", etag)
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNotModified, res.StatusCode)
+	})
+
+	t.Run("error not found", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/65c217fc6556430b3dc4ce61", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "anime not found", bodyJson.Detail)
+	})
+
+	t.Run("error id invalid", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/bukanid", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "anime id invalid", bodyJson.Detail)
+	})
+
+	err = dbutility.AnimeDeleteOneById(id)
+	require.Nil(t, err)
+}
+
+func TestAnimePatch(t *testing.T) {
+
+	id, err := dbutility.AnimeAdd(UserID, "testingpatch", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	genreID, err := dbutility.GenreAdd(&requestbody.Genre{Name: "testingpatch"})
+	require.Nil(t, err)
+
+	t.Run("success merge patch", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPatch, Server.URL+"/api/anime/"+id, bytes.NewReader([]byte(`{"status":"completed"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/merge-patch+json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Msg{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "patch anime success", bodyJson.Message)
+	})
+
+	t.Run("success json patch", func(t *testing.T) {
+
+		patch := `[{"op":"add","path":"/genre_ids/-","value":"` + genreID + `"},{"op":"replace","path":"/description","value":"ubah"}]`
+		request, err := http.NewRequest(http.MethodPatch, Server.URL+"/api/anime/"+id, bytes.NewReader([]byte(patch)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json-patch+json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Msg{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "patch anime success", bodyJson.Message)
+	})
+
+	t.Run("validation error present field only", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPatch, Server.URL+"/api/anime/"+id, bytes.NewReader([]byte(`{"name":""}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/merge-patch+json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, 1, len(bodyJson.Errors))
+	})
+
+	t.Run("content type error", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPatch, Server.URL+"/api/anime/"+id, bytes.NewReader([]byte(`{"status":"completed"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+		assert.NotEmpty(t, res.Header.Get("Accept-Patch"))
+	})
+
+	t.Run("validation error unknown genre", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPatch, Server.URL+"/api/anime/"+id, bytes.NewReader([]byte(`{"genre_ids":["65c217fc6556430b3dc4ce61"]}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/merge-patch+json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, bodyJson.Errors)
+		assert.Equal(t, "genre_ids", bodyJson.Errors[0].Field)
+		assert.Equal(t, "exists", bodyJson.Errors[0].Rule)
+		assert.Equal(t, "65c217fc6556430b3dc4ce61", bodyJson.Errors[0].Param)
+	})
+
+	err = dbutility.AnimeDeleteOneById(id)
+	require.Nil(t, err)
+
+	err = dbutility.GenreDeleteById(genreID)
+	require.Nil(t, err)
+}
+
+// progressStatus reads the status of anime id back through the api.
+func progressStatus(t *testing.T, id string) string {
+
+	request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/"+id, nil)
+	require.Nil(t, err)
+
+	request.AddCookie(&http.Cookie{
+		Name:     "token",
+		Value:    TokenUser,
+		Expires:  time.Now().Add(time.Hour * 24),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	client := &http.Client{}
+	res, err := client.Do(request)
+	require.Nil(t, err)
+
+	body := res.Body
+	defer body.Close()
+
+	bodyByte, err := io.ReadAll(body)
+	require.Nil(t, err)
+
+	bodyJson := response.AnimeOne{}
+	err = json.Unmarshal(bodyByte, &bodyJson)
+	require.Nil(t, err)
+
+	return string(bodyJson.Data.Status)
+}
+
+func TestAnimeProgress(t *testing.T) {
+
+	id, err := dbutility.AnimeAdd(UserID, "testingprogress", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeSetEpisodes(id, 2, 1)
+	require.Nil(t, err)
+
+	t.Run("success increment to completed", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+id+"/progress", bytes.NewReader([]byte(`{"action":"increment"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.AnimeOne{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 2, bodyJson.Data.WatchedEpisodes)
+		assert.Equal(t, "completed", string(bodyJson.Data.Status))
+	})
+
+	t.Run("success decrement back to rewatching", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+id+"/progress", bytes.NewReader([]byte(`{"action":"decrement"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.AnimeOne{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 1, bodyJson.Data.WatchedEpisodes)
+		assert.Equal(t, "rewatching", string(bodyJson.Data.Status))
+	})
+
+	t.Run("success patch to completed", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPatch, Server.URL+"/api/anime/"+id, bytes.NewReader([]byte(`{"watched_episodes":2}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/merge-patch+json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "completed", progressStatus(t, id))
+	})
+
+	t.Run("success update below total back to rewatching", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Anime{
+			Name:            "testingprogress",
+			Description:     "lorem",
+			GenreIds:        []string{GenreID},
+			Image:           "https://example.com",
+			Status:          "completed",
+			TotalEpisodes:   2,
+			WatchedEpisodes: 1,
+		})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/anime/"+id, bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "rewatching", progressStatus(t, id))
+	})
+
+	t.Run("success on hold is not completed", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPatch, Server.URL+"/api/anime/"+id, bytes.NewReader([]byte(`{"status":"on-hold"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/merge-patch+json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		res.Body.Close()
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		request, err = http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+id+"/progress", bytes.NewReader([]byte(`{"action":"increment"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		res, err = client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.AnimeOne{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		// the transitions don't allow on-hold to completed
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 2, bodyJson.Data.WatchedEpisodes)
+		assert.Equal(t, "on-hold", string(bodyJson.Data.Status))
+	})
+
+	t.Run("validation error out of range", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+id+"/progress", bytes.NewReader([]byte(`{"action":"set","value":3}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, bodyJson.Errors)
+		assert.Equal(t, "watched_episodes", bodyJson.Errors[0].Field)
+		assert.Equal(t, "max", bodyJson.Errors[0].Rule)
+		assert.Equal(t, "2", bodyJson.Errors[0].Param)
+	})
+
+	err = dbutility.AnimeDeleteOneById(id)
+	require.Nil(t, err)
+}
+
+func TestAnimeReview(t *testing.T) {
+
+	id, err := dbutility.AnimeAdd(UserID, "testingreview", "https://example.com", []string{GenreID}, "lorem", "completed")
+	require.Nil(t, err)
+
+	t.Run("success set", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/anime/"+id+"/review", bytes.NewReader([]byte(`{"score":8.5,"review":"**great** ending"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Msg{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "set review success", bodyJson.Message)
+	})
+
+	t.Run("success sort by score", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime?sort=score&order=desc&limit=1", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.AnimeAll{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, bodyJson.Data, 1)
+		require.NotNil(t, bodyJson.Data[0].Score)
+		assert.Equal(t, 8.5, *bodyJson.Data[0].Score)
+		assert.Equal(t, "**great** ending", bodyJson.Data[0].Review)
+	})
+
+	t.Run("validation error score not half point", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/anime/"+id+"/review", bytes.NewReader([]byte(`{"score":7.3}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, bodyJson.Errors)
+		assert.Equal(t, "score", bodyJson.Errors[0].Field)
+		assert.Equal(t, "half_point", bodyJson.Errors[0].Rule)
+		assert.Equal(t, "score harus antara 1 dan 10 dengan kelipatan 0,5", bodyJson.Errors[0].Message)
+	})
+
+	t.Run("success clear", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/anime/"+id+"/review", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Msg{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "clear review success", bodyJson.Message)
+	})
+
+	err = dbutility.AnimeDeleteOneById(id)
+	require.Nil(t, err)
 }
@@ -3,26 +3,173 @@ package test
 import (
 	"bytes"
 	"encoding/json"
+	"history_anime/src/entity"
+	"history_anime/src/middlewares"
 	"history_anime/src/requestbody"
 	"history_anime/src/response"
 	"history_anime/src/utility"
 	"history_anime/test/dbutility"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+func TestRegister(t *testing.T) {
+
+	t.Run("conflict email already exists", func(t *testing.T) {
+
+		body, _ := json.Marshal(requestbody.Register{
+			Username: "hasanlain",
+			Email:    "HASAN@gmail.com",
+			Password: "Rahasia-Testing-2024",
+		})
+
+		res, err := http.Post(Server.URL+"/api/register", "application/json", bytes.NewReader(body))
+		require.Nil(t, err)
+
+		bodyResult := res.Body
+
+		bodyByte, err := io.ReadAll(bodyResult)
+		require.Nil(t, err)
+		defer bodyResult.Close()
+
+		resBody := response.Problem{}
+		err = json.Unmarshal(bodyByte, &resBody)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, "email already exists", resBody.Detail)
+	})
+
+	t.Run("conflict username already exists", func(t *testing.T) {
+
+		body, _ := json.Marshal(requestbody.Register{
+			Username: "Hasan",
+			Email:    "hasanlain@gmail.com",
+			Password: "Rahasia-Testing-2024",
+		})
+
+		res, err := http.Post(Server.URL+"/api/register", "application/json", bytes.NewReader(body))
+		require.Nil(t, err)
+
+		bodyResult := res.Body
+
+		bodyByte, err := io.ReadAll(bodyResult)
+		require.Nil(t, err)
+		defer bodyResult.Close()
+
+		resBody := response.Problem{}
+		err = json.Unmarshal(bodyByte, &resBody)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, "username already exists", resBody.Detail)
+	})
+
+	t.Run("validation error email and username", func(t *testing.T) {
+
+		body, _ := json.Marshal(requestbody.Register{
+			Username: "hasan baru",
+			Email:    "hasanbaru",
+			Password: "Rahasia-Testing-2024",
+		})
+
+		res, err := http.Post(Server.URL+"/api/register", "application/json", bytes.NewReader(body))
+		require.Nil(t, err)
+
+		bodyResult := res.Body
+
+		bodyByte, err := io.ReadAll(bodyResult)
+		require.Nil(t, err)
+		defer bodyResult.Close()
+
+		resBody := response.Problem{}
+		err = json.Unmarshal(bodyByte, &resBody)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Len(t, resBody.Errors, 2)
+		assert.Equal(t, "username", resBody.Errors[0].Field)
+		assert.Equal(t, "username", resBody.Errors[0].Rule)
+		assert.Equal(t, "email", resBody.Errors[1].Field)
+		assert.Equal(t, "email", resBody.Errors[1].Rule)
+	})
+
+	t.Run("validation error weak password", func(t *testing.T) {
+
+		body, _ := json.Marshal(requestbody.Register{
+			Username: "hasanbaru",
+			Email:    "hasanbaru@gmail.com",
+			Password: "abc",
+		})
+
+		res, err := http.Post(Server.URL+"/api/register", "application/json", bytes.NewReader(body))
+		require.Nil(t, err)
+
+		bodyResult := res.Body
+
+		bodyByte, err := io.ReadAll(bodyResult)
+		require.Nil(t, err)
+		defer bodyResult.Close()
+
+		resBody := response.Problem{}
+		err = json.Unmarshal(bodyByte, &resBody)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Len(t, resBody.Errors, 3)
+		assert.Equal(t, "password", resBody.Errors[0].Field)
+		assert.Equal(t, "min", resBody.Errors[0].Rule)
+		assert.Equal(t, "password", resBody.Errors[1].Field)
+		assert.Equal(t, "contains_upper", resBody.Errors[1].Rule)
+		assert.Equal(t, "password", resBody.Errors[2].Field)
+		assert.Equal(t, "contains_digit", resBody.Errors[2].Rule)
+	})
+
+	t.Run("validation error common password", func(t *testing.T) {
+
+		body, _ := json.Marshal(requestbody.Register{
+			Username: "hasanbaru",
+			Email:    "hasanbaru@gmail.com",
+			Password: "Password123",
+		})
+
+		res, err := http.Post(Server.URL+"/api/register", "application/json", bytes.NewReader(body))
+		require.Nil(t, err)
+
+		bodyResult := res.Body
+
+		bodyByte, err := io.ReadAll(bodyResult)
+		require.Nil(t, err)
+		defer bodyResult.Close()
+
+		resBody := response.Problem{}
+		err = json.Unmarshal(bodyByte, &resBody)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, resBody.Errors)
+		assert.Equal(t, "password", resBody.Errors[0].Field)
+		assert.Equal(t, "not_common", resBody.Errors[0].Rule)
+	})
+}
+
 func TestLogin(t *testing.T) {
 
 	var loginTesting = requestbody.Register{
 		Username: "testing",
 		Email:    "testing@gmail.com",
-		Password: "123",
+		Password: "Rahasia-Testing-2024",
 	}
 
 	loginTestingByte, _ := json.Marshal(loginTesting)
@@ -75,13 +222,13 @@ func TestLogin(t *testing.T) {
 		require.Nil(t, err)
 		defer bodyResult.Close()
 
-		resBody := response.Errors{}
+		resBody := response.Problem{}
 		err = json.Unmarshal(bodyByte, &resBody)
 		require.Nil(t, err)
 
 		cookie := res.Cookies()
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "check email or password", resBody.Errors[0])
+		assert.Equal(t, "check email or password", resBody.Detail)
 		assert.Equal(t, len(cookie), 0)
 	})
 
@@ -99,13 +246,13 @@ func TestLogin(t *testing.T) {
 		require.Nil(t, err)
 		defer bodyResult.Close()
 
-		resBody := response.Errors{}
+		resBody := response.Problem{}
 		err = json.Unmarshal(bodyByte, &resBody)
 		require.Nil(t, err)
 
 		cookie := res.Cookies()
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "check email or password", resBody.Errors[0])
+		assert.Equal(t, "check email or password", resBody.Detail)
 		assert.Equal(t, len(cookie), 0)
 	})
 
@@ -124,13 +271,15 @@ func TestLogin(t *testing.T) {
 		require.Nil(t, err)
 		defer bodyResult.Close()
 
-		resBody := response.Errors{}
+		resBody := response.Problem{}
 		err = json.Unmarshal(bodyByte, &resBody)
 		require.Nil(t, err)
 
 		cookie := res.Cookies()
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "Error:Field validation for 'Email' failed on the 'required' tag", resBody.Errors[0])
+		require.NotEmpty(t, resBody.Errors)
+		assert.Equal(t, "email", resBody.Errors[0].Field)
+		assert.Equal(t, "required", resBody.Errors[0].Rule)
 		assert.Equal(t, len(cookie), 0)
 	})
 
@@ -220,13 +369,15 @@ func TestForgotPassword(t *testing.T) {
 		resBodyByte, err := io.ReadAll(result)
 		require.Nil(t, err)
 
-		resBody := response.Errors{}
+		resBody := response.Problem{}
 
 		err = json.Unmarshal(resBodyByte, &resBody)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "Error:Field validation for 'Email' failed on the 'required' tag", resBody.Errors[0])
+		require.NotEmpty(t, resBody.Errors)
+		assert.Equal(t, "email", resBody.Errors[0].Field)
+		assert.Equal(t, "required", resBody.Errors[0].Rule)
 
 	})
 }
@@ -238,7 +389,7 @@ func TestResetPassword(t *testing.T) {
 		var resetTesting = requestbody.Register{
 			Username: "testingreset",
 			Email:    "testing@gmail.com",
-			Password: "123",
+			Password: "Rahasia-Testing-2024",
 		}
 
 		loginTestingByte, _ := json.Marshal(resetTesting)
@@ -253,7 +404,7 @@ func TestResetPassword(t *testing.T) {
 		require.Nil(t, err)
 
 		bodyResetPassword := requestbody.ResetPassword{
-			NewPassword: "1234",
+			NewPassword: "Rahasia-Baru-2024",
 			Token:       token,
 		}
 
@@ -290,25 +441,68 @@ func TestResetPassword(t *testing.T) {
 		bodyByte, err := io.ReadAll(bodyResult)
 		require.Nil(t, err)
 
-		resBodyLogin := response.Errors{}
+		resBodyLogin := response.Problem{}
 		err = json.Unmarshal(bodyByte, &resBodyLogin)
 		require.Nil(t, err)
 
 		cookie := res.Cookies()
 		assert.Equal(t, http.StatusBadRequest, resLogin.StatusCode)
-		assert.Equal(t, "check email or password", resBodyLogin.Errors[0])
+		assert.Equal(t, "check email or password", resBodyLogin.Detail)
 		assert.Equal(t, len(cookie), 0)
 
 		err = dbutility.DeleteUser(resetTesting.Email)
 		require.Nil(t, err)
 	})
 
+	t.Run("success email in other case", func(t *testing.T) {
+
+		var resetTesting = requestbody.Register{
+			Username: "testingresetcase",
+			Email:    "testingcase@gmail.com",
+			Password: "Rahasia-Testing-2024",
+		}
+
+		registerByte, _ := json.Marshal(resetTesting)
+
+		resp, err := http.Post(Server.URL+"/api/register", "application/json", bytes.NewReader(registerByte))
+		require.Nil(t, err)
+		defer resp.Body.Close()
+
+		token, err := utility.CreateTokenForgotPassword(os.Getenv("SECRET_KEY"), strings.ToUpper(resetTesting.Email))
+		require.Nil(t, err)
+
+		bodyResetPasswordByte, err := json.Marshal(requestbody.ResetPassword{
+			NewPassword: "Rahasia-Baru-2024",
+			Token:       token,
+		})
+		require.Nil(t, err)
+
+		res, err := http.Post(Server.URL+"/api/reset-password", "application/json", bytes.NewReader(bodyResetPasswordByte))
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		body := response.Msg{}
+		err = json.Unmarshal(resBodyByte, &body)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "reset password success", body.Message)
+
+		err = dbutility.DeleteUser(resetTesting.Email)
+		require.Nil(t, err)
+	})
+
 	t.Run("error token invalid", func(t *testing.T) {
 
 		var resetTesting = requestbody.Register{
 			Username: "testingtokeninvalid",
 			Email:    "testing@gmail.com",
-			Password: "123",
+			Password: "Rahasia-Testing-2024",
 		}
 
 		loginTestingByte, _ := json.Marshal(resetTesting)
@@ -326,7 +520,7 @@ func TestResetPassword(t *testing.T) {
 		require.Nil(t, err)
 
 		bodyResetPassword := requestbody.ResetPassword{
-			NewPassword: "1234",
+			NewPassword: "Rahasia-Baru-2024",
 			Token:       token,
 		}
 
@@ -343,12 +537,12 @@ func TestResetPassword(t *testing.T) {
 		resBodyByte, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		body := response.Errors{}
+		body := response.Problem{}
 		err = json.Unmarshal(resBodyByte, &body)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "token invalid", body.Errors[0])
+		assert.Equal(t, "token invalid", body.Detail)
 
 		err = dbutility.DeleteUser(resetTesting.Email)
 		require.Nil(t, err)
@@ -359,7 +553,7 @@ func TestResetPassword(t *testing.T) {
 		require.Nil(t, err)
 
 		bodyResetPassword := requestbody.ResetPassword{
-			NewPassword: "1234",
+			NewPassword: "Rahasia-Baru-2024",
 			Token:       token,
 		}
 
@@ -376,12 +570,13 @@ func TestResetPassword(t *testing.T) {
 		resBodyByte, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		body := response.Errors{}
+		body := response.Problem{}
 		err = json.Unmarshal(resBodyByte, &body)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "type token error", body.Errors[0])
+		assert.Equal(t, "RESET_TOKEN_INVALID", body.Code)
+		assert.Equal(t, "token invalid", body.Detail)
 	})
 
 }
@@ -391,7 +586,7 @@ func TestIsLogin(t *testing.T) {
 		var isLoginTesting = requestbody.Register{
 			Username: "testingtokeninvalid",
 			Email:    "testing@gmail.com",
-			Password: "123",
+			Password: "Rahasia-Testing-2024",
 		}
 
 		loginTestingByte, _ := json.Marshal(isLoginTesting)
@@ -453,12 +648,12 @@ func TestIsLogin(t *testing.T) {
 		resBodyByte, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		body := response.Errors{}
+		body := response.Problem{}
 		err = json.Unmarshal(resBodyByte, &body)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
-		assert.Equal(t, "user is not logged in", body.Errors[0])
+		assert.Equal(t, "user is not logged in", body.Detail)
 	})
 
 	t.Run("jwt error type", func(t *testing.T) {
@@ -489,12 +684,268 @@ func TestIsLogin(t *testing.T) {
 		resBodyByte, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		body := response.Errors{}
+		body := response.Problem{}
 		err = json.Unmarshal(resBodyByte, &body)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
-		assert.Equal(t, "user is not logged in", body.Errors[0])
+		assert.Equal(t, "user is not logged in", body.Detail)
+
+	})
+}
+
+func TestOnlyLogin(t *testing.T) {
+
+	t.Run("token expired", func(t *testing.T) {
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"id":  primitive.NewObjectID().Hex(),
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		}).SignedString([]byte(os.Getenv("SECRET_KEY")))
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    token,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
 
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		body := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &body)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, "TOKEN_EXPIRED", body.Code)
+		assert.Equal(t, "token expired", body.Detail)
 	})
+
+	t.Run("token invalid", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    "not-a-token",
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		body := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &body)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Equal(t, "UNAUTHORIZED", body.Code)
+		assert.NotContains(t, body.Detail, "token is malformed")
+	})
+
+	t.Run("user in context", func(t *testing.T) {
+
+		var user entity.Users
+		var ok bool
+		handler := middlewares.OnlyLogin(func(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+			user, ok = utility.UserFromContext(r.Context())
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/api/genre/"+GenreID, nil)
+		request.AddCookie(&http.Cookie{
+			Name:  "token",
+			Value: TokenUser,
+		})
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, request, nil)
+
+		require.True(t, ok)
+		assert.Equal(t, UserID, user.Id.Hex())
+		assert.Equal(t, "hasan@gmail.com", user.Email)
+		assert.Empty(t, user.Password)
+	})
+}
+
+func TestLanguage(t *testing.T) {
+	var languageTesting = requestbody.Register{
+		Username: "testinglanguage",
+		Email:    "testinglanguage@gmail.com",
+		Password: "Rahasia-Testing-2024",
+		Language: "id",
+	}
+
+	registerByte, _ := json.Marshal(languageTesting)
+
+	resp, err := http.Post(Server.URL+"/api/register", "application/json", bytes.NewReader(registerByte))
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	id, err := dbutility.FindUser(languageTesting.Email)
+	require.Nil(t, err)
+
+	token, err := utility.CreateToken(os.Getenv("SECRET_KEY"), id)
+	require.Nil(t, err)
+
+	t.Run("accept language", func(t *testing.T) {
+		body, _ := json.Marshal(requestbody.Login{
+			Email:    "nobody@gmail.com",
+			Password: "Rahasia-Testing-2024",
+		})
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/login", bytes.NewReader(body))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "periksa email atau password", resBodyJson.Detail)
+	})
+
+	t.Run("user preference", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/65c217fc6556430b3dc4ce61", nil)
+		require.Nil(t, err)
+
+		request.Header.Set("Accept-Language", "en")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    token,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "anime tidak ditemukan", resBodyJson.Detail)
+	})
+
+	t.Run("update preference", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/language", bytes.NewReader([]byte(`{"language":"en"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    token,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Msg{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "language updated", resBodyJson.Message)
+	})
+
+	t.Run("validation error language", func(t *testing.T) {
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/language", bytes.NewReader([]byte(`{"language":"fr"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    token,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, resBodyJson.Errors)
+		assert.Equal(t, "language", resBodyJson.Errors[0].Field)
+		assert.Equal(t, "language", resBodyJson.Errors[0].Rule)
+		assert.Equal(t, "en id", resBodyJson.Errors[0].Param)
+	})
+
+	err = dbutility.DeleteUser(languageTesting.Email)
+	require.Nil(t, err)
 }
@@ -0,0 +1,234 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/test/dbutility"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimeBulk(t *testing.T) {
+
+	updateID, err := dbutility.AnimeAdd(UserID, "testingbulkupdate", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	deleteID, err := dbutility.AnimeAdd(UserID, "testingbulkdelete", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	t.Run("success with per item errors", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.AnimeBulk{
+			Operations: []requestbody.AnimeBulkOperation{
+				{Op: "create", Data: &requestbody.Anime{Name: "testingbulkcreate", Description: "lorem", Image: "https://example.com", GenreIds: []string{GenreID}, Tags: []string{"testingbulktag"}, Status: "plan-to-watch"}},
+				{Op: "update", Id: updateID, Data: &requestbody.Anime{Name: "testingbulkupdate", Description: "lorem", Image: "https://example.com", GenreIds: []string{GenreID}, Status: "completed"}},
+				{Op: "delete", Id: deleteID},
+				{Op: "delete", Id: "65c5c8634a978c8f77b310b2"},
+			},
+		})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/bulk", bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.AnimeBulk{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, resBodyJson.Results, 4)
+		assert.Equal(t, "success", resBodyJson.Results[0].Status)
+		assert.NotEmpty(t, resBodyJson.Results[0].Id)
+		assert.Equal(t, "success", resBodyJson.Results[1].Status)
+		assert.Equal(t, "success", resBodyJson.Results[2].Status)
+		assert.Equal(t, "error", resBodyJson.Results[3].Status)
+		require.NotEmpty(t, resBodyJson.Results[3].Errors)
+		assert.Equal(t, "id", resBodyJson.Results[3].Errors[0].Field)
+		assert.Equal(t, "exists", resBodyJson.Results[3].Errors[0].Rule)
+
+		_, err = dbutility.AnimeFindOne("testingbulkdelete")
+		assert.NotNil(t, err)
+
+		request, err = http.NewRequest(http.MethodGet, Server.URL+"/api/tag", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		res, err = client.Do(request)
+		require.Nil(t, err)
+
+		tagBody := res.Body
+		defer tagBody.Close()
+
+		tagByte, err := io.ReadAll(tagBody)
+		require.Nil(t, err)
+
+		tags := response.TagAll{}
+		err = json.Unmarshal(tagByte, &tags)
+		require.Nil(t, err)
+
+		// the tag is created together with the batch
+		names := []string{}
+		for _, tag := range tags.Data {
+			names = append(names, tag.Name)
+		}
+		assert.Contains(t, names, "testingbulktag")
+	})
+
+	t.Run("update follows progress", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.AnimeBulk{
+			Operations: []requestbody.AnimeBulkOperation{
+				{Op: "update", Id: updateID, Data: &requestbody.Anime{Name: "testingbulkupdate", Description: "lorem", Image: "https://example.com", GenreIds: []string{GenreID}, Status: "completed", TotalEpisodes: 2, WatchedEpisodes: 1}},
+			},
+		})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/bulk", bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "rewatching", progressStatus(t, updateID))
+	})
+
+	t.Run("atomic rejects the whole batch", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.AnimeBulk{
+			Operations: []requestbody.AnimeBulkOperation{
+				{Op: "create", Data: &requestbody.Anime{Name: "testingbulkatomic", Description: "lorem", Image: "https://example.com", GenreIds: []string{GenreID}, Status: "watching"}},
+				{Op: "update", Id: updateID},
+			},
+		})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/bulk?atomic=true", bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.AnimeBulk{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Len(t, resBodyJson.Results, 2)
+		assert.Equal(t, "skipped", resBodyJson.Results[0].Status)
+		assert.Equal(t, "error", resBodyJson.Results[1].Status)
+
+		_, err = dbutility.AnimeFindOne("testingbulkatomic")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("not found other segment", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+updateID, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "not found", resBodyJson.Detail)
+	})
+
+	err = dbutility.AnimeDeleteOne("testingbulkcreate")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeDeleteOneById(updateID)
+	require.Nil(t, err)
+
+	err = dbutility.TagDeleteByUser(UserID)
+	require.Nil(t, err)
+}
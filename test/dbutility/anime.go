@@ -83,12 +83,27 @@ func AnimeDeleteOneById(id string) error {
 	return nil
 }
 
-func AnimeAdd(name string, image string, genre []string, description string, status string) (string, error) {
+func AnimeAdd(userID string, name string, image string, genreIDs []string, description string, status string) (string, error) {
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	objGenreIDs := []primitive.ObjectID{}
+	for _, genreID := range genreIDs {
+		objGenreID, err := primitive.ObjectIDFromHex(genreID)
+		if err != nil {
+			return "", errors.New(err.Error())
+		}
+		objGenreIDs = append(objGenreIDs, objGenreID)
+	}
 
 	insertDoc := bson.D{
+		{Key: "user_id", Value: objUserID},
 		{Key: "name", Value: name},
 		{Key: "image", Value: image},
-		{Key: "genre", Value: genre},
+		{Key: "genre_ids", Value: objGenreIDs},
 		{Key: "description", Value: description},
 		{Key: "status", Value: status},
 		{Key: "created_at", Value: primitive.NewDateTimeFromTime(time.Now())},
@@ -109,3 +124,104 @@ func AnimeAdd(name string, image string, genre []string, description string, sta
 	return insertID.Hex(), nil
 
 }
+
+func AnimeSetEpisodes(id string, total int, watched int) error {
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	ctx := context.Background()
+	updateDoc := bson.D{
+		{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "total_episodes", Value: total},
+				{Key: "watched_episodes", Value: watched},
+			},
+		},
+	}
+
+	_, err = db.DB.Collection("anime").UpdateByID(ctx, objID, updateDoc)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+func AnimeSetScore(id string, score float64) error {
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	ctx := context.Background()
+	updateDoc := bson.D{
+		{
+			Key: "$set",
+			Value: bson.D{
+				{Key: "score", Value: score},
+			},
+		},
+	}
+
+	_, err = db.DB.Collection("anime").UpdateByID(ctx, objID, updateDoc)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+// AnimeUnsetStatus removes the status, like entries stored before it existed.
+func AnimeUnsetStatus(id string) error {
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	ctx := context.Background()
+	updateDoc := bson.D{
+		{
+			Key: "$unset",
+			Value: bson.D{
+				{Key: "status", Value: ""},
+			},
+		},
+	}
+
+	_, err = db.DB.Collection("anime").UpdateByID(ctx, objID, updateDoc)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+func AnimeGenreIds(id string) ([]string, error) {
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	ctx := context.Background()
+	data := struct {
+		GenreIds []primitive.ObjectID `bson:"genre_ids"`
+	}{}
+	err = db.DB.Collection("anime").FindOne(ctx, bson.D{{Key: "_id", Value: objID}}).Decode(&data)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	result := []string{}
+	for _, genreID := range data.GenreIds {
+		result = append(result, genreID.Hex())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package dbutility
+
+import (
+	"context"
+	"errors"
+	"history_anime/src/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func EpisodeCount(animeID string) (int64, error) {
+
+	objID, err := primitive.ObjectIDFromHex(animeID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	ctx := context.Background()
+	filter := bson.D{
+		{
+			Key:   "anime_id",
+			Value: objID,
+		},
+	}
+
+	count, err := db.DB.Collection("anime_episodes").CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return count, nil
+}
+
+func EpisodeDeleteByAnime(animeID string) error {
+
+	objID, err := primitive.ObjectIDFromHex(animeID)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	ctx := context.Background()
+	filter := bson.D{
+		{
+			Key:   "anime_id",
+			Value: objID,
+		},
+	}
+
+	_, err = db.DB.Collection("anime_episodes").DeleteMany(ctx, filter)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
@@ -84,3 +84,34 @@ func GenreAdd(data *requestbody.Genre) (string, error) {
 
 	return id.Hex(), nil
 }
+
+func GenreAddChild(name string, parentID string) (string, error) {
+	ctx := context.Background()
+
+	objParentID, err := primitive.ObjectIDFromHex(parentID)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	insertDoc := bson.D{
+		{
+			Key:   "name",
+			Value: name,
+		},
+		{
+			Key:   "parent_id",
+			Value: objParentID,
+		},
+	}
+	result, err := db.DB.Collection("genre").InsertOne(ctx, insertDoc)
+	if err != nil {
+		return "", errors.New(err.Error())
+	}
+
+	id, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return "", errors.New("type error")
+	}
+
+	return id.Hex(), nil
+}
@@ -0,0 +1,33 @@
+package dbutility
+
+import (
+	"context"
+	"errors"
+	"history_anime/src/db"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TagDeleteByUser(userID string) error {
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	ctx := context.Background()
+	filter := bson.D{
+		{
+			Key:   "user_id",
+			Value: objUserID,
+		},
+	}
+
+	_, err = db.DB.Collection("tag").DeleteMany(ctx, filter)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
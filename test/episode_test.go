@@ -0,0 +1,227 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"history_anime/src/response"
+	"history_anime/test/dbutility"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpisode(t *testing.T) {
+
+	id, err := dbutility.AnimeAdd(UserID, "testingepisode", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeSetEpisodes(id, 12, 0)
+	require.Nil(t, err)
+
+	episodeID := ""
+
+	t.Run("success add", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+id+"/episodes", bytes.NewReader([]byte(`{"episode":1,"note":"great opening"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.EpisodeInsert{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "insert episode success", bodyJson.Message)
+		episodeID = bodyJson.InsertedID
+	})
+
+	t.Run("validation error episode out of range", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+id+"/episodes", bytes.NewReader([]byte(`{"episode":13}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, bodyJson.Errors)
+		assert.Equal(t, "episode", bodyJson.Errors[0].Field)
+		assert.Equal(t, "max", bodyJson.Errors[0].Rule)
+		assert.Equal(t, "12", bodyJson.Errors[0].Param)
+	})
+
+	t.Run("success get all", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/"+id+"/episodes", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.EpisodeAll{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, bodyJson.Data, 1)
+		assert.Equal(t, 1, bodyJson.Data[0].Episode)
+		assert.Equal(t, "great opening", bodyJson.Data[0].Note)
+	})
+
+	t.Run("success update", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/anime/"+id+"/episodes/"+episodeID, bytes.NewReader([]byte(`{"episode":2,"note":"rewatched"}`)))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Msg{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "update episode success", bodyJson.Message)
+	})
+
+	t.Run("error episode id invalid", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/anime/"+id+"/episodes/123", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		body := res.Body
+		defer body.Close()
+
+		bodyByte, err := io.ReadAll(body)
+		require.Nil(t, err)
+
+		bodyJson := response.Problem{}
+		err = json.Unmarshal(bodyByte, &bodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "episode id invalid", bodyJson.Detail)
+	})
+
+	t.Run("success delete anime cascades", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/anime/"+id, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		count, err := dbutility.EpisodeCount(id)
+		require.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/test/dbutility"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport(t *testing.T) {
+
+	id, err := dbutility.AnimeAdd(UserID, "testingexport", "https://example.com", []string{GenreID}, "lorem", "completed")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeSetEpisodes(id, 12, 12)
+	require.Nil(t, err)
+
+	t.Run("success csv", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/export?format=csv", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		records, err := csv.NewReader(resBody).ReadAll()
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.True(t, strings.HasPrefix(res.Header.Get("Content-Disposition"), "attachment;"))
+		assert.True(t, strings.HasSuffix(res.Header.Get("Content-Disposition"), `.csv"`))
+		require.Len(t, records, 2)
+		assert.Equal(t, "name", records[0][0])
+		assert.Equal(t, []string{"testingexport", "completed", GenreName}, records[1][:3])
+	})
+
+	t.Run("success mal-xml", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/export?format=mal-xml", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyXml := requestbody.MalExport{}
+		err = xml.NewDecoder(resBody).Decode(&resBodyXml)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, resBodyXml.Anime, 1)
+		assert.Equal(t, "testingexport", resBodyXml.Anime[0].Title)
+		assert.Equal(t, "Completed", resBodyXml.Anime[0].Status)
+		assert.Equal(t, 12, resBodyXml.Anime[0].WatchedEpisodes)
+	})
+
+	t.Run("validation error format", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/export?format=pdf", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, resBodyJson.Errors)
+		assert.Equal(t, "format", resBodyJson.Errors[0].Field)
+		assert.Equal(t, "oneof", resBodyJson.Errors[0].Rule)
+	})
+
+	err = dbutility.AnimeDeleteOneById(id)
+	require.Nil(t, err)
+}
@@ -3,11 +3,14 @@ package test
 import (
 	"bytes"
 	"encoding/json"
+	"history_anime/src/entity"
+	"history_anime/src/repository"
 	"history_anime/src/requestbody"
 	"history_anime/src/response"
 	"history_anime/test/dbutility"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -62,6 +65,43 @@ func TestGenreAdd(t *testing.T) {
 		require.Nil(t, err)
 	})
 
+	t.Run("conflict name already exists", func(t *testing.T) {
+
+		bodyInsertByte, err := json.Marshal(requestbody.Genre{Name: strings.ToUpper(GenreName) + " "})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/genre", bytes.NewReader(bodyInsertByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, "genre name already exists", resBodyJson.Detail)
+	})
+
 	t.Run("content type error", func(t *testing.T) {
 
 		bodyInsert := requestbody.Genre{
@@ -94,12 +134,13 @@ func TestGenreAdd(t *testing.T) {
 		resBodyByte, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		resBodyJson := response.Errors{}
+		resBodyJson := response.Problem{}
 		err = json.Unmarshal(resBodyByte, &resBodyJson)
 		require.Nil(t, err)
 
-		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
-		assert.Equal(t, "content-type must be application/json", resBodyJson.Errors[0])
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+		assert.Equal(t, "CONTENT_TYPE_INVALID", resBodyJson.Code)
+		assert.Equal(t, "content-type must be application/json", resBodyJson.Detail)
 	})
 }
 
@@ -168,13 +209,530 @@ func TestGenreDelete(t *testing.T) {
 		resBodyByte, err := io.ReadAll(resBody)
 		require.Nil(t, err)
 
-		resBodyJson := response.Errors{}
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "genre not found", resBodyJson.Detail)
+	})
+
+	t.Run("conflict genre in use", func(t *testing.T) {
+
+		id, err := dbutility.GenreAdd(&requestbody.Genre{
+			Name: "contohdipakai",
+		})
+		require.Nil(t, err)
+
+		animeID, err := dbutility.AnimeAdd(UserID, "testinggenreconflict", "https://example.com", []string{id}, "lorem", "watching")
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/genre/"+id, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := struct {
+			response.Problem
+			AnimeCount int64 `json:"anime_count"`
+		}{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, "GENRE_IN_USE", resBodyJson.Code)
+		assert.Equal(t, int64(1), resBodyJson.AnimeCount)
+
+		err = dbutility.AnimeDeleteOneById(animeID)
+		require.Nil(t, err)
+
+		err = dbutility.GenreDeleteById(id)
+		require.Nil(t, err)
+	})
+
+	t.Run("success detach", func(t *testing.T) {
+
+		id, err := dbutility.GenreAdd(&requestbody.Genre{
+			Name: "contohdetach",
+		})
+		require.Nil(t, err)
+
+		animeID, err := dbutility.AnimeAdd(UserID, "testinggenredetach", "https://example.com", []string{id, GenreID}, "lorem", "watching")
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/genre/"+id+"?strategy=detach", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.GenreDelete{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, int64(1), resBodyJson.AnimeUpdated)
+
+		genreIds, err := dbutility.AnimeGenreIds(animeID)
+		require.Nil(t, err)
+		assert.Equal(t, []string{GenreID}, genreIds)
+
+		err = dbutility.AnimeDeleteOneById(animeID)
+		require.Nil(t, err)
+	})
+
+	t.Run("success reassign", func(t *testing.T) {
+
+		id, err := dbutility.GenreAdd(&requestbody.Genre{
+			Name: "contohreassign",
+		})
+		require.Nil(t, err)
+
+		animeID, err := dbutility.AnimeAdd(UserID, "testinggenrereassign", "https://example.com", []string{id}, "lorem", "watching")
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/genre/"+id+"?strategy=reassign&to="+GenreID, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.GenreDelete{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, int64(1), resBodyJson.AnimeUpdated)
+
+		genreIds, err := dbutility.AnimeGenreIds(animeID)
+		require.Nil(t, err)
+		assert.Equal(t, []string{GenreID}, genreIds)
+
+		err = dbutility.AnimeDeleteOneById(animeID)
+		require.Nil(t, err)
+	})
+
+	t.Run("validation error reassign without target", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/genre/"+GenreID+"?strategy=reassign", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, resBodyJson.Errors)
+		assert.Equal(t, "to", resBodyJson.Errors[0].Field)
+		assert.Equal(t, "required_if", resBodyJson.Errors[0].Rule)
+	})
+}
+
+func TestGenreUpdate(t *testing.T) {
+
+	id, err := dbutility.GenreAdd(&requestbody.Genre{
+		Name: "contohtypo",
+	})
+	require.Nil(t, err)
+
+	animeID, err := dbutility.AnimeAdd(UserID, "testinggenrerename", "https://example.com", []string{id}, "lorem", "watching")
+	require.Nil(t, err)
+
+	t.Run("success", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Genre{Name: "contohbenar"})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/genre/"+id, bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Msg{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "update genre success", resBodyJson.Message)
+	})
+
+	t.Run("anime shows new name", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime/"+animeID, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.AnimeOne{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"contohbenar"}, resBodyJson.Data.Genre)
+	})
+
+	t.Run("conflict name taken", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Genre{Name: strings.ToUpper(GenreName) + " "})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/genre/"+id, bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, "genre name already exists", resBodyJson.Detail)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Genre{Name: "contohlain"})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/genre/65c5c8634a978c8f77b310b2", bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
 		err = json.Unmarshal(resBodyByte, &resBodyJson)
 		require.Nil(t, err)
 
 		assert.Equal(t, http.StatusNotFound, res.StatusCode)
-		assert.Equal(t, "genre not found", resBodyJson.Errors[0])
+		assert.Equal(t, "genre not found", resBodyJson.Detail)
+	})
+
+	err = dbutility.AnimeDeleteOneById(animeID)
+	require.Nil(t, err)
+
+	err = dbutility.GenreDeleteById(id)
+	require.Nil(t, err)
+}
+
+func TestGenreHierarchy(t *testing.T) {
+
+	parentID, err := dbutility.GenreAdd(&requestbody.Genre{
+		Name: "contohinduk",
+	})
+	require.Nil(t, err)
+
+	childID, err := dbutility.GenreAddChild("contohanak", parentID)
+	require.Nil(t, err)
+
+	animeID, err := dbutility.AnimeAdd(UserID, "testinggenrechild", "https://example.com", []string{childID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	t.Run("filter by parent includes children", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/anime?genre="+parentID, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.AnimeAll{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, resBodyJson.Data, 1)
+		assert.Equal(t, "testinggenrechild", resBodyJson.Data[0].Name)
+	})
+
+	t.Run("bad request parent cycle", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Genre{Name: "contohinduk", ParentId: childID})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/genre/"+parentID, bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "GENRE_CYCLE", resBodyJson.Code)
+		assert.Equal(t, repository.ErrGenreCycle.Error(), resBodyJson.Detail)
 	})
+
+	err = dbutility.AnimeDeleteOneById(animeID)
+	require.Nil(t, err)
+
+	err = dbutility.GenreDeleteById(childID)
+	require.Nil(t, err)
+
+	err = dbutility.GenreDeleteById(parentID)
+	require.Nil(t, err)
+}
+
+func TestGenreStats(t *testing.T) {
+
+	id, err := dbutility.GenreAdd(&requestbody.Genre{
+		Name: "contohstatistik",
+	})
+	require.Nil(t, err)
+
+	firstID, err := dbutility.AnimeAdd(UserID, "testinggenrestats1", "https://example.com", []string{id}, "lorem", "watching")
+	require.Nil(t, err)
+
+	secondID, err := dbutility.AnimeAdd(UserID, "testinggenrestats2", "https://example.com", []string{id}, "lorem", "completed")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeSetScore(secondID, 8.5)
+	require.Nil(t, err)
+
+	legacyID, err := dbutility.AnimeAdd(UserID, "testinggenrestats3", "https://example.com", []string{id}, "lorem", "watching")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeUnsetStatus(legacyID)
+	require.Nil(t, err)
+
+	request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/genre/stats", nil)
+	require.Nil(t, err)
+
+	request.AddCookie(&http.Cookie{
+		Name:     "token",
+		Value:    TokenUser,
+		Expires:  time.Now().Add(time.Hour * 24),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	client := &http.Client{}
+	res, err := client.Do(request)
+	require.Nil(t, err)
+
+	resBody := res.Body
+	defer resBody.Close()
+
+	resBodyByte, err := io.ReadAll(resBody)
+	require.Nil(t, err)
+
+	resBodyJson := response.GenreStats{}
+	err = json.Unmarshal(resBodyByte, &resBodyJson)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	var stats *entity.GenreStats
+	for i := range resBodyJson.Data {
+		if resBodyJson.Data[i].Id.Hex() == id {
+			stats = &resBodyJson.Data[i]
+		}
+	}
+	require.NotNil(t, stats)
+	assert.Equal(t, int64(3), stats.Total)
+	assert.Equal(t, int64(1), stats.Status["unknown"])
+	assert.Equal(t, int64(1), stats.Status["watching"])
+	assert.Equal(t, int64(1), stats.Status["completed"])
+	assert.Equal(t, int64(0), stats.Status["dropped"])
+	require.NotNil(t, stats.AverageScore)
+	assert.Equal(t, 8.5, *stats.AverageScore)
+
+	err = dbutility.AnimeDeleteOneById(firstID)
+	require.Nil(t, err)
+
+	err = dbutility.AnimeDeleteOneById(secondID)
+	require.Nil(t, err)
+
+	err = dbutility.AnimeDeleteOneById(legacyID)
+	require.Nil(t, err)
+
+	err = dbutility.GenreDeleteById(id)
+	require.Nil(t, err)
 }
 
 func TestGenreGetAllSuccess(t *testing.T) {
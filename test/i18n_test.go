@@ -0,0 +1,32 @@
+package test
+
+import (
+	"history_anime/src/i18n"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogueParity(t *testing.T) {
+
+	keys := func(section map[string]string) []string {
+		result := []string{}
+		for key := range section {
+			result = append(result, key)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	base := i18n.Get(i18n.Languages[0])
+	for _, lang := range i18n.Languages[1:] {
+		t.Run(lang, func(t *testing.T) {
+			catalogue := i18n.Get(lang)
+
+			assert.Equal(t, keys(base.Messages), keys(catalogue.Messages), "messages")
+			assert.Equal(t, keys(base.ValidationTags), keys(catalogue.ValidationTags), "validation_tags")
+			assert.Equal(t, keys(base.ValidationChecks), keys(catalogue.ValidationChecks), "validation_checks")
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"history_anime/src/response"
+	"history_anime/test/dbutility"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const malExport = `<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<myinfo>
+		<user_name>hasan</user_name>
+	</myinfo>
+	<anime>
+		<series_title><![CDATA[testing import new]]></series_title>
+		<series_episodes>12</series_episodes>
+		<my_watched_episodes>12</my_watched_episodes>
+		<my_score>9</my_score>
+		<my_status>Completed</my_status>
+		<my_tags><![CDATA[mal]]></my_tags>
+	</anime>
+	<anime>
+		<series_title><![CDATA[TESTING IMPORT EXISTING]]></series_title>
+		<series_episodes>24</series_episodes>
+		<my_watched_episodes>24</my_watched_episodes>
+		<my_score>0</my_score>
+		<my_status>Completed</my_status>
+	</anime>
+	<anime>
+		<series_title><![CDATA[testing import new]]></series_title>
+		<series_episodes>12</series_episodes>
+		<my_watched_episodes>3</my_watched_episodes>
+		<my_score>0</my_score>
+		<my_status>Watching</my_status>
+	</anime>
+	<anime>
+		<series_title><![CDATA[testing import bad]]></series_title>
+		<series_episodes>0</series_episodes>
+		<my_watched_episodes>0</my_watched_episodes>
+		<my_score>0</my_score>
+		<my_status>Unknown</my_status>
+	</anime>
+</myanimelist>`
+
+func TestImportMal(t *testing.T) {
+
+	existingID, err := dbutility.AnimeAdd(UserID, "testing import existing", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	t.Run("success", func(t *testing.T) {
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		err := writer.WriteField("genre", "testingimportgenre")
+		require.Nil(t, err)
+		part, err := writer.CreateFormFile("file", "animelist.xml")
+		require.Nil(t, err)
+		_, err = part.Write([]byte(malExport))
+		require.Nil(t, err)
+		err = writer.Close()
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/import/mal", body)
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Import{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 1, resBodyJson.Created)
+		assert.Equal(t, 1, resBodyJson.Updated)
+		assert.Equal(t, 2, resBodyJson.Skipped)
+		require.Len(t, resBodyJson.Rows, 4)
+		assert.Equal(t, "created", resBodyJson.Rows[0].Result)
+		assert.Equal(t, "updated", resBodyJson.Rows[1].Result)
+		assert.Equal(t, existingID, resBodyJson.Rows[1].Id)
+		assert.Equal(t, "duplicate in file", resBodyJson.Rows[2].Reason)
+		assert.Equal(t, "unknown status 'Unknown'", resBodyJson.Rows[3].Reason)
+	})
+
+	t.Run("watched and tags are checked", func(t *testing.T) {
+
+		export := `<myanimelist>
+	<anime>
+		<series_title><![CDATA[testing import existing]]></series_title>
+		<series_episodes>0</series_episodes>
+		<my_watched_episodes>30</my_watched_episodes>
+		<my_score>0</my_score>
+		<my_status>Completed</my_status>
+	</anime>
+	<anime>
+		<series_title><![CDATA[testing import tags]]></series_title>
+		<series_episodes>12</series_episodes>
+		<my_watched_episodes>0</my_watched_episodes>
+		<my_score>0</my_score>
+		<my_status>Plan to Watch</my_status>
+		<my_tags><![CDATA[` + strings.Repeat("a", 51) + `]]></my_tags>
+	</anime>
+</myanimelist>`
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "animelist.xml")
+		require.Nil(t, err)
+		_, err = part.Write([]byte(export))
+		require.Nil(t, err)
+		err = writer.Close()
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/import/mal", body)
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Import{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, resBodyJson.Rows, 2)
+		assert.Equal(t, "skipped", resBodyJson.Rows[1].Result)
+		assert.Equal(t, "invalid tags", resBodyJson.Rows[1].Reason)
+		require.NotEmpty(t, resBodyJson.Rows[1].Errors)
+		assert.Equal(t, "max", resBodyJson.Rows[1].Errors[0].Rule)
+
+		request, err = http.NewRequest(http.MethodGet, Server.URL+"/api/anime/"+existingID, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		res, err = client.Do(request)
+		require.Nil(t, err)
+
+		animeBody := res.Body
+		defer animeBody.Close()
+
+		animeByte, err := io.ReadAll(animeBody)
+		require.Nil(t, err)
+
+		anime := response.AnimeOne{}
+		err = json.Unmarshal(animeByte, &anime)
+		require.Nil(t, err)
+
+		// the file has no episode count, the stored 24 from the first import bounds it
+		assert.Equal(t, 24, anime.Data.TotalEpisodes)
+		assert.Equal(t, 24, anime.Data.WatchedEpisodes)
+	})
+
+	t.Run("decompressed too large", func(t *testing.T) {
+
+		// about 100 KiB of gzip that expands past the 50 MiB cap
+		compressed := &bytes.Buffer{}
+		gz := gzip.NewWriter(compressed)
+		_, err := gz.Write([]byte("<myanimelist>"))
+		require.Nil(t, err)
+		padding := bytes.Repeat([]byte(" "), 1<<20)
+		for i := 0; i < 51; i++ {
+			_, err = gz.Write(padding)
+			require.Nil(t, err)
+		}
+		err = gz.Close()
+		require.Nil(t, err)
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "animelist.xml.gz")
+		require.Nil(t, err)
+		_, err = part.Write(compressed.Bytes())
+		require.Nil(t, err)
+		err = writer.Close()
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/import/mal", body)
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", writer.FormDataContentType())
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+		assert.Equal(t, "IMPORT_TOO_LARGE", resBodyJson.Code)
+	})
+
+	t.Run("content type error", func(t *testing.T) {
+
+		// a type that only starts with multipart/form-data is rejected too
+		for _, contentType := range []string{"application/xml", "multipart/form-datax; boundary=x"} {
+			request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/import/mal", bytes.NewReader([]byte(malExport)))
+			require.Nil(t, err)
+
+			request.Header.Set("Content-Type", contentType)
+			request.AddCookie(&http.Cookie{
+				Name:     "token",
+				Value:    TokenUser,
+				Expires:  time.Now().Add(time.Hour * 24),
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   true,
+				SameSite: http.SameSiteNoneMode,
+			})
+
+			client := &http.Client{}
+			res, err := client.Do(request)
+			require.Nil(t, err)
+
+			resBody := res.Body
+			defer resBody.Close()
+
+			resBodyByte, err := io.ReadAll(resBody)
+			require.Nil(t, err)
+
+			resBodyJson := response.Problem{}
+			err = json.Unmarshal(resBodyByte, &resBodyJson)
+			require.Nil(t, err)
+
+			assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode, contentType)
+			assert.Equal(t, "content-type must be multipart/form-data", resBodyJson.Detail)
+		}
+	})
+
+	err = dbutility.AnimeDeleteOne("testing import new")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeDeleteOneById(existingID)
+	require.Nil(t, err)
+
+	err = dbutility.GenreDeleteByName("testingimportgenre")
+	require.Nil(t, err)
+
+	err = dbutility.TagDeleteByUser(UserID)
+	require.Nil(t, err)
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"history_anime/src/db"
+	"history_anime/src/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMigrateAnimeOwner(t *testing.T) {
+
+	ctx := context.Background()
+	insert, err := db.DB.Collection("anime").InsertOne(ctx, bson.D{
+		{Key: "name", Value: "testing ownerless"},
+		{Key: "status", Value: "watching"},
+	})
+	require.Nil(t, err)
+	id := insert.InsertedID.(primitive.ObjectID)
+
+	t.Run("without owner", func(t *testing.T) {
+		err := db.MigrateAnimeOwner(ctx, db.DB, "")
+		require.Nil(t, err)
+
+		result := entity.Anime{}
+		err = db.DB.Collection("anime").FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&result)
+		require.Nil(t, err)
+		assert.True(t, result.UserId.IsZero())
+	})
+
+	t.Run("unknown owner", func(t *testing.T) {
+		err := db.MigrateAnimeOwner(ctx, db.DB, primitive.NewObjectID().Hex())
+		assert.NotNil(t, err)
+	})
+
+	t.Run("with owner", func(t *testing.T) {
+		err := db.MigrateAnimeOwner(ctx, db.DB, UserID)
+		require.Nil(t, err)
+
+		result := entity.Anime{}
+		err = db.DB.Collection("anime").FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&result)
+		require.Nil(t, err)
+		assert.Equal(t, UserID, result.UserId.Hex())
+	})
+
+	_, err = db.DB.Collection("anime").DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	require.Nil(t, err)
+}
+
+func TestMigrateDuplicateUsers(t *testing.T) {
+
+	ctx := context.Background()
+
+	t.Run("no duplicates", func(t *testing.T) {
+		err := db.MigrateDuplicateUsers(ctx, db.DB)
+		assert.Nil(t, err)
+	})
+
+	t.Run("duplicates are reported", func(t *testing.T) {
+		// the unique indexes already exist on db.DB
+		scratch := db.DB.Client().Database("history-anime-migrate-test")
+		defer scratch.Drop(ctx)
+
+		_, err := scratch.Collection("users").InsertMany(ctx, []interface{}{
+			bson.D{{Key: "username", Value: "hasan"}, {Key: "email", Value: "hasan@gmail.com"}},
+			bson.D{{Key: "username", Value: "hasanlain"}, {Key: "email", Value: "HASAN@gmail.com"}},
+			bson.D{{Key: "username", Value: "Hasanlain"}, {Key: "email", Value: "hasanlain@gmail.com"}},
+		})
+		require.Nil(t, err)
+
+		err = db.MigrateDuplicateUsers(ctx, scratch)
+		require.NotNil(t, err)
+		assert.Contains(t, err.Error(), "email hasan@gmail.com")
+		assert.Contains(t, err.Error(), "HASAN@gmail.com")
+		assert.Contains(t, err.Error(), "username hasanlain")
+		assert.NotContains(t, err.Error(), "username hasan (")
+	})
+}
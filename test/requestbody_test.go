@@ -0,0 +1,143 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/test/dbutility"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBody(t *testing.T) {
+
+	send := func(contentType string, body []byte) (*http.Response, response.Problem) {
+
+		req, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime", bytes.NewReader(body))
+		require.Nil(t, err)
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(req)
+		require.Nil(t, err)
+		defer res.Body.Close()
+
+		resBodyByte, err := io.ReadAll(res.Body)
+		require.Nil(t, err)
+
+		problem := response.Problem{}
+		json.Unmarshal(resBodyByte, &problem)
+
+		return res, problem
+	}
+
+	dataInsert := requestbody.Anime{
+		Name:        "testing request body",
+		Description: "lorem",
+		GenreIds:    []string{GenreID},
+		Image:       "https://example.com",
+		Status:      "watching",
+	}
+
+	bodyByte, err := json.Marshal(dataInsert)
+	require.Nil(t, err)
+
+	t.Run("success with charset", func(t *testing.T) {
+		res, _ := send("application/json; charset=utf-8", bodyByte)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		err := dbutility.AnimeDeleteOne(dataInsert.Name)
+		require.Nil(t, err)
+	})
+
+	t.Run("unsupported media type", func(t *testing.T) {
+		res, problem := send("text/plain", bodyByte)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+		assert.Equal(t, "CONTENT_TYPE_INVALID", problem.Code)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		res, problem := send("application/json", []byte(`{"name":"testing","nmae":"typo"}`))
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "UNKNOWN_FIELD", problem.Code)
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		res, problem := send("application/json", append(append([]byte{}, bodyByte...), []byte(`{"name":"again"}`)...))
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "INVALID_JSON", problem.Code)
+		assert.Equal(t, "request body must only contain a single json value", problem.Detail)
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		res, problem := send("application/json", nil)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "INVALID_JSON", problem.Code)
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		large := requestbody.Anime{
+			Name:        "testing",
+			Description: strings.Repeat("a", int(requestbody.MaxBodySize)),
+			GenreIds:    []string{GenreID},
+			Image:       "https://example.com",
+			Status:      "watching",
+		}
+
+		largeByte, err := json.Marshal(large)
+		require.Nil(t, err)
+
+		res, problem := send("application/json", largeByte)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+		assert.Equal(t, "BODY_TOO_LARGE", problem.Code)
+	})
+}
+
+func TestLoginContentType(t *testing.T) {
+
+	body, err := json.Marshal(requestbody.Login{
+		Email:    "hasan@gmail.com",
+		Password: "Rahasia-Hasan-2024",
+	})
+	require.Nil(t, err)
+
+	res, err := http.Post(Server.URL+"/api/login", "text/plain", bytes.NewReader(body))
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	resBodyByte, err := io.ReadAll(res.Body)
+	require.Nil(t, err)
+
+	problem := response.Problem{}
+	err = json.Unmarshal(resBodyByte, &problem)
+	require.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+	assert.Equal(t, "CONTENT_TYPE_INVALID", problem.Code)
+	assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+}
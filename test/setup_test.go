@@ -18,12 +18,16 @@ import (
 
 var Server *httptest.Server
 var TokenUser string
+var UserID string
+var GenreID string
+
+const GenreName = "testinganimegenre"
 
 func setupUser() {
 	var bodyRegister = requestbody.Register{
 		Username: "hasan",
 		Email:    "hasan@gmail.com",
-		Password: "123",
+		Password: "Rahasia-Hasan-2024",
 	}
 	bodyRegisterByte, _ := json.Marshal(bodyRegister)
 
@@ -45,6 +49,11 @@ func setupUser() {
 
 	cookie := res.Cookies()[0]
 	TokenUser = cookie.Value
+
+	UserID, err = dbutility.FindUser(bodyRegister.Email)
+	if err != nil {
+		panic(err)
+	}
 }
 
 func TestMain(m *testing.M) {
@@ -56,6 +65,12 @@ func TestMain(m *testing.M) {
 	db.CreateConnection(ctx)
 	Server = httptest.NewServer(routers.Router())
 	setupUser()
+
+	GenreID, err = dbutility.GenreAdd(&requestbody.Genre{Name: GenreName})
+	if err != nil {
+		panic(err)
+	}
+
 	m.Run()
 	defer db.CloseDB(ctx)
 	defer Server.Close()
@@ -64,4 +79,9 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		panic(err)
 	}
+
+	err = dbutility.GenreDeleteById(GenreID)
+	if err != nil {
+		panic(err)
+	}
 }
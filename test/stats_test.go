@@ -0,0 +1,141 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"history_anime/src/response"
+	"history_anime/test/dbutility"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+
+	completedID, err := dbutility.AnimeAdd(UserID, "testingstats1", "https://example.com", []string{GenreID}, "lorem", "completed")
+	require.Nil(t, err)
+
+	watchingID, err := dbutility.AnimeAdd(UserID, "testingstats2", "https://example.com", []string{GenreID}, "lorem", "watching")
+	require.Nil(t, err)
+
+	planID, err := dbutility.AnimeAdd(UserID, "testingstats3", "https://example.com", []string{GenreID}, "lorem", "plan-to-watch")
+	require.Nil(t, err)
+
+	err = dbutility.AnimeSetEpisodes(watchingID, 12, 0)
+	require.Nil(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime/"+watchingID+"/episodes", bytes.NewReader([]byte(`{"episode":1}`)))
+	require.Nil(t, err)
+
+	request.Header.Set("Content-Type", "application/json")
+	request.AddCookie(&http.Cookie{
+		Name:     "token",
+		Value:    TokenUser,
+		Expires:  time.Now().Add(time.Hour * 24),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	client := &http.Client{}
+	res, err := client.Do(request)
+	require.Nil(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	t.Run("success", func(t *testing.T) {
+
+		today := time.Now().UTC().Format(time.DateOnly)
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/stats?from="+today+"&to="+today, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Stats{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, int64(3), resBodyJson.Data.Total)
+		assert.Equal(t, int64(1), resBodyJson.Data.Status["completed"])
+		assert.Equal(t, int64(0), resBodyJson.Data.Status["dropped"])
+		assert.Equal(t, 0.5, resBodyJson.Data.CompletionRate)
+		require.Len(t, resBodyJson.Data.AddedPerMonth, 1)
+		assert.Equal(t, int64(3), resBodyJson.Data.AddedPerMonth[0].Count)
+		require.Len(t, resBodyJson.Data.EpisodesPerWeek, 1)
+		assert.Equal(t, int64(1), resBodyJson.Data.EpisodesPerWeek[0].Count)
+		require.Len(t, resBodyJson.Data.TopGenres, 1)
+		assert.Equal(t, GenreName, resBodyJson.Data.TopGenres[0].Name)
+	})
+
+	t.Run("validation error from after to", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodGet, Server.URL+"/api/stats?from=2024-02-01&to=2024-01-01", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NotEmpty(t, resBodyJson.Errors)
+		assert.Equal(t, "from", resBodyJson.Errors[0].Field)
+		assert.Equal(t, "ltefield", resBodyJson.Errors[0].Rule)
+		assert.Equal(t, "to", resBodyJson.Errors[0].Param)
+	})
+
+	err = dbutility.AnimeDeleteOneById(completedID)
+	require.Nil(t, err)
+
+	err = dbutility.AnimeDeleteOneById(watchingID)
+	require.Nil(t, err)
+
+	err = dbutility.EpisodeDeleteByAnime(watchingID)
+	require.Nil(t, err)
+
+	err = dbutility.AnimeDeleteOneById(planID)
+	require.Nil(t, err)
+}
@@ -0,0 +1,339 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"history_anime/src/requestbody"
+	"history_anime/src/response"
+	"history_anime/test/dbutility"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTag(t *testing.T) {
+
+	tagID := ""
+
+	t.Run("success add", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Tag{Name: "Isekai"})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/tag", bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.TagInsert{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, "insert tag success", resBodyJson.Message)
+		tagID = resBodyJson.InsertedID
+	})
+
+	t.Run("conflict name already exists", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Tag{Name: "isekai "})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/tag", bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+		assert.Equal(t, "tag name already exists", resBodyJson.Detail)
+	})
+
+	t.Run("validation error blank name", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Tag{Name: "   "})
+		require.Nil(t, err)
+
+		for _, endpoint := range []struct {
+			method string
+			url    string
+		}{
+			{http.MethodPost, Server.URL + "/api/tag"},
+			{http.MethodPut, Server.URL + "/api/tag/" + tagID},
+		} {
+			request, err := http.NewRequest(endpoint.method, endpoint.url, bytes.NewReader(bodyByte))
+			require.Nil(t, err)
+
+			request.Header.Set("Content-Type", "application/json")
+			request.AddCookie(&http.Cookie{
+				Name:     "token",
+				Value:    TokenUser,
+				Expires:  time.Now().Add(time.Hour * 24),
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   true,
+				SameSite: http.SameSiteNoneMode,
+			})
+
+			client := &http.Client{}
+			res, err := client.Do(request)
+			require.Nil(t, err)
+
+			resBody := res.Body
+			defer resBody.Close()
+
+			resBodyByte, err := io.ReadAll(resBody)
+			require.Nil(t, err)
+
+			resBodyJson := response.Problem{}
+			err = json.Unmarshal(resBodyByte, &resBodyJson)
+			require.Nil(t, err)
+
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode, endpoint.method)
+			require.NotEmpty(t, resBodyJson.Errors)
+			assert.Equal(t, "name", resBodyJson.Errors[0].Field)
+			assert.Equal(t, "required", resBodyJson.Errors[0].Rule)
+		}
+	})
+
+	t.Run("anime creates tags on the fly", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Anime{
+			Name:        "testingtag",
+			Description: "lorem",
+			GenreIds:    []string{GenreID},
+			Tags:        []string{"isekai", "Time-Loop"},
+			Image:       "https://example.com",
+			Status:      "watching",
+		})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, Server.URL+"/api/anime", bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		request, err = http.NewRequest(http.MethodGet, Server.URL+"/api/tag", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		res, err = client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.TagAll{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		names := []string{}
+		for _, tag := range resBodyJson.Data {
+			names = append(names, tag.Name)
+		}
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"isekai", "time-loop"}, names)
+	})
+
+	t.Run("success rename updates anime", func(t *testing.T) {
+
+		bodyByte, err := json.Marshal(requestbody.Tag{Name: "another world"})
+		require.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPut, Server.URL+"/api/tag/"+tagID, bytes.NewReader(bodyByte))
+		require.Nil(t, err)
+
+		request.Header.Set("Content-Type", "application/json")
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+		res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		request, err = http.NewRequest(http.MethodGet, Server.URL+"/api/anime?tag=another%20world", nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		res, err = client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.AnimeAll{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.Len(t, resBodyJson.Data, 1)
+		assert.ElementsMatch(t, []string{"another world", "time-loop"}, resBodyJson.Data[0].Tags)
+	})
+
+	t.Run("success delete", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/tag/"+tagID, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Msg{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "delete tag success", resBodyJson.Message)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+
+		request, err := http.NewRequest(http.MethodDelete, Server.URL+"/api/tag/"+tagID, nil)
+		require.Nil(t, err)
+
+		request.AddCookie(&http.Cookie{
+			Name:     "token",
+			Value:    TokenUser,
+			Expires:  time.Now().Add(time.Hour * 24),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteNoneMode,
+		})
+
+		client := &http.Client{}
+		res, err := client.Do(request)
+		require.Nil(t, err)
+
+		resBody := res.Body
+		defer resBody.Close()
+
+		resBodyByte, err := io.ReadAll(resBody)
+		require.Nil(t, err)
+
+		resBodyJson := response.Problem{}
+		err = json.Unmarshal(resBodyByte, &resBodyJson)
+		require.Nil(t, err)
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "tag not found", resBodyJson.Detail)
+	})
+
+	err := dbutility.AnimeDeleteOne("testingtag")
+	require.Nil(t, err)
+
+	err = dbutility.TagDeleteByUser(UserID)
+	require.Nil(t, err)
+}